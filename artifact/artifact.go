@@ -2,6 +2,7 @@ package artifact
 
 import (
 	"bytes"
+	"encoding/csv"
 	"fmt"
 	"net/url"
 	"sort"
@@ -14,19 +15,21 @@ const dateformat = "01-02-2006"
 
 // Artifact represents a work product.
 type Artifact struct {
-	Title       string    `yaml:"title,omitempty"`
-	Link        string    `yaml:"link,omitempty"`
-	Type        string    `yaml:"type,omitempty"`
-	Project     string    `yaml:"project,omitempty"`
-	Subproject  string    `yaml:"subproject,omitempty"`
-	Role        string    `yaml:"role,omitempty"`
-	ShippedDate time.Time `yaml:"shipped_date,omitempty"`
-	Extra       string    `yaml:"extra,omitempty"`
+	Title       string    `yaml:"title,omitempty" json:"title,omitempty"`
+	Link        string    `yaml:"link,omitempty" json:"link,omitempty"`
+	Type        string    `yaml:"type,omitempty" json:"type,omitempty"`
+	Project     string    `yaml:"project,omitempty" json:"project,omitempty"`
+	Subproject  string    `yaml:"subproject,omitempty" json:"subproject,omitempty"`
+	Role        string    `yaml:"role,omitempty" json:"role,omitempty"`
+	ShippedDate time.Time `yaml:"shipped_date,omitempty" json:"shipped_date,omitempty"`
+	Extra       string    `yaml:"extra,omitempty" json:"extra,omitempty"`
+	// Attributes holds custom columns, keyed by their header
+	Attributes map[string]string `yaml:"attributes,omitempty" json:"attributes,omitempty"`
 }
 
 // Copy returns an exact duplicate of an artifact
 func (a Artifact) Copy() Artifact {
-	return Artifact{
+	result := Artifact{
 		Type:        a.Type,
 		Project:     a.Project,
 		Subproject:  a.Subproject,
@@ -34,13 +37,23 @@ func (a Artifact) Copy() Artifact {
 		Role:        a.Role,
 		ShippedDate: a.ShippedDate,
 		Link:        a.Link,
+		Extra:       a.Extra,
 	}
+
+	if a.Attributes != nil {
+		result.Attributes = map[string]string{}
+		for k, v := range a.Attributes {
+			result.Attributes[k] = v
+		}
+	}
+
+	return result
 }
 
-// String returns a string representation of an artifact
+// String returns a comma separated representation of an artifact, quoting
+// any fields that need it
 func (a Artifact) String() string {
-	return fmt.Sprintf(
-		"%s,%s,%s,%s,%s,%s,%s",
+	fields := []string{
 		a.Type,
 		a.Project,
 		a.Subproject,
@@ -48,12 +61,30 @@ func (a Artifact) String() string {
 		a.Role,
 		a.ShippedDate.Format(dateformat),
 		a.Link,
-	)
+	}
+
+	var sb strings.Builder
+
+	w := csv.NewWriter(&sb)
+	if err := w.Write(fields); err != nil {
+		return strings.Join(fields, ",")
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return strings.Join(fields, ",")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
 }
 
 // Hyperlink formats artifact to be a Google Sheet hyperlink
 func (a Artifact) Hyperlink() string {
-	u := a.Link
+	return fmt.Sprintf("=HYPERLINK(\"%s\",\"%s\")", a.Link, a.LinkTitle())
+}
+
+// LinkTitle returns the text shown for the artifact's link in a sheet, which
+// shortens some well known links
+func (a Artifact) LinkTitle() string {
 	title := a.Link
 
 	if strings.Contains(a.Link, "critique.corp.google.com") {
@@ -78,23 +109,34 @@ func (a Artifact) Hyperlink() string {
 
 	}
 
-	return fmt.Sprintf("=HYPERLINK(\"%s\",\"%s\")", u, title)
+	return title
+}
+
+// ToInterfaces converts an artifact to a single row in the format that gsheet
+// requires for data input
+func (a Artifact) ToInterfaces() []interface{} {
+	return a.Row(Header)
 }
 
 // Artifacts is a collection of Artifact items
 type Artifacts []Artifact
 
 // ToInterfaces converts artifacts to the slice of slice of interfaces format
-// that gsheet requires for data input
+// that gsheet requires for data input. Extra and custom attributes are added
+// as columns after the standard ones when any artifact has them.
 func (a Artifacts) ToInterfaces() [][]interface{} {
 	var result [][]interface{}
 
-	header := []interface{}{"Type", "Project", "Subproject", "Title", "Role", "Shipped Date", "Link"}
+	columns := a.Columns()
+
+	header := []interface{}{}
+	for _, v := range columns {
+		header = append(header, v)
+	}
 	result = append(result, header)
 
 	for _, v := range a {
-		myval := []interface{}{v.Type, v.Project, v.Subproject, v.Title, v.Role, v.ShippedDate.Format("01/02/2006"), v.Hyperlink()}
-		result = append(result, myval)
+		result = append(result, v.Row(columns))
 	}
 
 	return result
@@ -113,6 +155,23 @@ func uniform(s string) string {
 	return strings.ToLower(strings.TrimSpace(s))
 }
 
+// inFolder reports whether a folder path is the input folder or inside it,
+// allowing for the drive at the start of the path to be left off the folder
+func inFolder(path, folder string) bool {
+	folder = strings.Trim(folder, "/")
+	if path == "" || folder == "" {
+		return false
+	}
+
+	_, rest, _ := strings.Cut(path, "/")
+	for _, p := range []string{path, rest} {
+		if p == folder || strings.HasPrefix(p, folder+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 func urlMatch(u1, u2 string) bool {
 
 	if !strings.HasPrefix(u1, "http") {
@@ -170,12 +229,38 @@ func urlMatch(u1, u2 string) bool {
 	return true
 }
 
+// Matches reports whether the artifact points at the given link, adjusting
+// for shortcuts for buganizer and critique the same way Search does. It
+// allows one link to contain the other, so prefer SameLink when an exact
+// match is needed.
+func (a Artifact) Matches(link string) bool {
+	return urlMatch(a.Link, link)
+}
+
+// SameLink reports whether the artifact's link, or the title it is shown
+// with in a sheet, is exactly the given link, ignoring case, scheme and any
+// trailing slash
+func (a Artifact) SameLink(link string) bool {
+	l := normalLink(link)
+	if l == "" {
+		return false
+	}
+	return l == normalLink(a.Link) || l == normalLink(a.LinkTitle())
+}
+
+func normalLink(link string) string {
+	l := uniform(link)
+	l = strings.TrimPrefix(l, "https://")
+	l = strings.TrimPrefix(l, "http://")
+	return strings.TrimSuffix(l, "/")
+}
+
 // Search looks for an exact match for a given link in a given set of artifacts
 // it adjusts for shortcuts for buganizer and critique
 func (a Artifacts) Search(link string) (Artifact, bool) {
 	for _, art := range a {
 
-		if urlMatch(art.Link, link) {
+		if art.Matches(link) {
 			return art, true
 		}
 
@@ -434,8 +519,89 @@ func Classify(list Classifiers) Option {
 
 }
 
+// Override replaces the Type, Project and Subproject of artifacts with the
+// values from a matching override, so manual edits survive classification.
+// Blank fields in an override are ignored.
+func Override(overrides Artifacts) Option {
+	return func(a *Artifacts) {
+		for i, art := range *a {
+			for _, o := range overrides {
+				if o.Link == "" || !art.SameLink(o.Link) {
+					continue
+				}
+
+				if o.Type != "" {
+					art.Type = o.Type
+				}
+				if o.Project != "" {
+					art.Project = o.Project
+				}
+				if o.Subproject != "" {
+					art.Subproject = o.Subproject
+				}
+				(*a)[i] = art
+				break
+			}
+		}
+	}
+}
+
+// Edits returns the Type, Project and Subproject values in current that were
+// changed by hand, as overrides. A value is a hand edit when it differs from
+// the one in the first artifact in computed with the same link, which should
+// be what classification gave it when it was written. Values with nothing to
+// compare against are not edits.
+func Edits(current, computed Artifacts) Artifacts {
+	result := Artifacts{}
+
+	for _, cur := range current {
+		if cur.Link == "" {
+			continue
+		}
+
+		base, ok := Artifact{}, false
+		for _, c := range computed {
+			if c.SameLink(cur.Link) {
+				base, ok = c, true
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		edit := Artifact{Link: cur.Link}
+		if cur.Type != base.Type {
+			edit.Type = cur.Type
+		}
+		if cur.Project != base.Project {
+			edit.Project = cur.Project
+		}
+		if cur.Subproject != base.Subproject {
+			edit.Subproject = cur.Subproject
+		}
+
+		if edit.Type != "" || edit.Project != "" || edit.Subproject != "" {
+			result = append(result, edit)
+		}
+	}
+
+	return result
+}
+
+// Attributes that sources fill in and classifiers can match on
+const (
+	// FolderAttribute is the path of the folder an artifact lives in
+	FolderAttribute = "Folder"
+	// DriveAttribute is the name of the shared drive an artifact lives in
+	DriveAttribute = "Drive"
+)
+
 // Classifier is a data structure that is used for filling in missing data in
-// artifacts
+// artifacts. Contains maps the title, link, project, folder or drive of an
+// artifact to substrings that mark it as part of the Project. Folders are
+// paths instead, such as Projects/Atlas, which match that folder and the
+// ones inside it. The drive at the start of the path can be left off.
 type Classifier struct {
 	Project    string              `yaml:"project,omitempty"`
 	Subproject string              `yaml:"subproject,omitempty"`
@@ -499,6 +665,24 @@ func (c Classifiers) Stamp(art Artifact) Artifact {
 					}
 				}
 			}
+			if key == "folder" {
+				folder := uniform(art.Attributes[FolderAttribute])
+				for _, v := range value {
+					if inFolder(folder, uniform(v)) {
+						art.Project = list.Project
+						art.Subproject = list.Subproject
+					}
+				}
+			}
+			if key == "drive" {
+				drive := uniform(art.Attributes[DriveAttribute])
+				for _, v := range value {
+					if drive != "" && strings.Contains(drive, uniform(v)) {
+						art.Project = list.Project
+						art.Subproject = list.Subproject
+					}
+				}
+			}
 		}
 	}
 
@@ -24,6 +24,18 @@ func TestArtifactString(t *testing.T) {
 			},
 			want: "Type,Proj,Sub,Title,Role,08-21-2023,http://example.com",
 		},
+		"comma": {
+			in: Artifact{
+				Title:       "Title, with comma",
+				Type:        "Type",
+				Link:        "http://example.com",
+				Project:     "Proj",
+				Subproject:  "Sub",
+				Role:        "Role",
+				ShippedDate: time.Date(2023, 8, 21, 12, 0, 0, 0, time.UTC),
+			},
+			want: `Type,Proj,Sub,"Title, with comma",Role,08-21-2023,http://example.com`,
+		},
 	}
 
 	for name, tc := range tests {
@@ -59,6 +71,18 @@ func TestArtifactCopy(t *testing.T) {
 				ShippedDate: time.Date(2023, 8, 21, 12, 0, 0, 0, time.UTC),
 			},
 		},
+		"extra": {
+			in: Artifact{
+				Title:      "Title",
+				Extra:      "Extra",
+				Attributes: map[string]string{"Impact": "High"},
+			},
+			want: Artifact{
+				Title:      "Title",
+				Extra:      "Extra",
+				Attributes: map[string]string{"Impact": "High"},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -69,6 +93,44 @@ func TestArtifactCopy(t *testing.T) {
 	}
 }
 
+func TestArtifactCopyAttributesIndependent(t *testing.T) {
+	in := Artifact{Attributes: map[string]string{"Impact": "High"}}
+	got := in.Copy()
+	got.Attributes["Impact"] = "Low"
+
+	assert.Equal(t, "High", in.Attributes["Impact"])
+}
+
+func TestArtifactsColumns(t *testing.T) {
+	tests := map[string]struct {
+		in   Artifacts
+		want []string
+	}{
+		"standard": {
+			in:   Artifacts{Artifact{Title: "Title"}},
+			want: Header,
+		},
+		"extra": {
+			in:   Artifacts{Artifact{Title: "Title"}, Artifact{Extra: "Extra"}},
+			want: append(append([]string{}, Header...), "Extra"),
+		},
+		"attributes": {
+			in: Artifacts{
+				Artifact{Attributes: map[string]string{"OKR": "1"}},
+				Artifact{Attributes: map[string]string{"Impact": "High"}},
+			},
+			want: append(append([]string{}, Header...), "Impact", "OKR"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tc.in.Columns()
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestArtifactHyperlink(t *testing.T) {
 	tests := map[string]struct {
 		in   Artifact
@@ -108,6 +170,47 @@ func TestArtifactHyperlink(t *testing.T) {
 	}
 }
 
+func TestArtifactSameLink(t *testing.T) {
+	tests := map[string]struct {
+		in   Artifact
+		link string
+		want bool
+	}{
+		"exact": {
+			in:   Artifact{Link: "https://github.com/o/r/pull/1"},
+			link: "https://github.com/o/r/pull/1",
+			want: true,
+		},
+		"prefix": {
+			in:   Artifact{Link: "https://github.com/o/r/pull/12"},
+			link: "https://github.com/o/r/pull/1",
+			want: false,
+		},
+		"normalized": {
+			in:   Artifact{Link: "https://GitHub.com/o/r/pull/1/"},
+			link: "http://github.com/o/r/pull/1",
+			want: true,
+		},
+		"title": {
+			in:   Artifact{Link: "https://docs.google.com/document/d/abc/edit?usp=sharing"},
+			link: "https://docs.google.com/document/d/abc",
+			want: true,
+		},
+		"empty": {
+			in:   Artifact{},
+			link: "",
+			want: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tc.in.SameLink(tc.link)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestArtifactsToInterfaces(t *testing.T) {
 	tests := map[string]struct {
 		in   Artifacts
@@ -1055,6 +1158,137 @@ func TestArtifactsClassify(t *testing.T) {
 	}
 }
 
+func TestArtifactsOverride(t *testing.T) {
+	tests := map[string]struct {
+		in        Artifacts
+		overrides Artifacts
+		want      *Artifacts
+	}{
+		"match": {
+			in: Artifacts{
+				Artifact{Title: "Title", Type: "Doc", Project: "Computed", Subproject: "Computed", Link: "http://example.com/1"},
+				Artifact{Title: "Other", Project: "Computed", Link: "http://example.com/2"},
+			},
+			overrides: Artifacts{
+				Artifact{Type: "Design", Project: "Manual", Subproject: "Manual", Link: "http://example.com/1"},
+			},
+			want: &Artifacts{
+				Artifact{Title: "Title", Type: "Design", Project: "Manual", Subproject: "Manual", Link: "http://example.com/1"},
+				Artifact{Title: "Other", Project: "Computed", Link: "http://example.com/2"},
+			},
+		},
+		"blankfields": {
+			in: Artifacts{
+				Artifact{Title: "Title", Type: "Doc", Project: "Computed", Subproject: "Computed", Link: "http://example.com/1"},
+			},
+			overrides: Artifacts{
+				Artifact{Project: "Manual", Link: "http://example.com/1"},
+			},
+			want: &Artifacts{
+				Artifact{Title: "Title", Type: "Doc", Project: "Manual", Subproject: "Computed", Link: "http://example.com/1"},
+			},
+		},
+		"prefixlink": {
+			in: Artifacts{
+				Artifact{Title: "Twelve", Project: "Computed", Link: "https://github.com/o/r/pull/12"},
+				Artifact{Title: "One", Project: "Computed", Link: "https://github.com/o/r/pull/1"},
+			},
+			overrides: Artifacts{
+				Artifact{Project: "Manual", Link: "https://github.com/o/r/pull/1"},
+			},
+			want: &Artifacts{
+				Artifact{Title: "Twelve", Project: "Computed", Link: "https://github.com/o/r/pull/12"},
+				Artifact{Title: "One", Project: "Manual", Link: "https://github.com/o/r/pull/1"},
+			},
+		},
+		"nolink": {
+			in: Artifacts{
+				Artifact{Title: "Title", Project: "Computed", Link: "http://example.com/1"},
+			},
+			overrides: Artifacts{
+				Artifact{Project: "Manual"},
+			},
+			want: &Artifacts{
+				Artifact{Title: "Title", Project: "Computed", Link: "http://example.com/1"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tc.in.Massage(Override(tc.overrides))
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestEdits(t *testing.T) {
+	tests := map[string]struct {
+		current  Artifacts
+		computed Artifacts
+		want     Artifacts
+	}{
+		"unedited": {
+			current:  Artifacts{{Type: "Doc", Project: "Old", Link: "http://example.com/1"}},
+			computed: Artifacts{{Type: "Doc", Project: "Old", Link: "http://example.com/1"}},
+			want:     Artifacts{},
+		},
+		"edited": {
+			current:  Artifacts{{Type: "Doc", Project: "Manual", Subproject: "Sub", Link: "http://example.com/1"}},
+			computed: Artifacts{{Type: "Doc", Project: "Old", Subproject: "Sub", Link: "http://example.com/1"}},
+			want:     Artifacts{{Project: "Manual", Link: "http://example.com/1"}},
+		},
+		"firstcomputed": {
+			current: Artifacts{{Project: "Old", Link: "http://example.com/1"}},
+			computed: Artifacts{
+				{Project: "Old", Link: "http://example.com/1"},
+				{Project: "New", Link: "http://example.com/1"},
+			},
+			want: Artifacts{},
+		},
+		"norecord": {
+			current:  Artifacts{{Project: "Manual", Link: "http://example.com/1"}},
+			computed: Artifacts{{Project: "Old", Link: "http://example.com/12"}},
+			want:     Artifacts{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Edits(tc.current, tc.computed)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestEditsRuleChange(t *testing.T) {
+	rules := func(project string) Classifiers {
+		return Classifiers{Lists: []Classifier{
+			{Project: project, Contains: map[string][]string{"title": {"atlas"}}},
+		}}
+	}
+
+	collected := Artifacts{
+		{Title: "Atlas launch", Link: "http://example.com/1"},
+		{Title: "Atlas review", Link: "http://example.com/2"},
+	}
+
+	// what the last run wrote, with the second row since edited by hand
+	record := collected.Copy()
+	record.Massage(Classify(rules("Atlas")))
+	current := record.Copy()
+	current[1].Project = "Manual"
+
+	// the rule has changed since
+	got := collected.Copy()
+	got.Massage(Classify(rules("Atlas Next")))
+	classified := got.Copy()
+	got.Massage(Override(Edits(current, append(record, classified...))))
+
+	assert.Equal(t, "Atlas Next", got[0].Project)
+	assert.Equal(t, "Manual", got[1].Project)
+}
+
 func TestClassifierStamp(t *testing.T) {
 	tests := map[string]struct {
 		classifiers Classifiers
@@ -1103,6 +1337,129 @@ func TestClassifierStamp(t *testing.T) {
 				Subproject: "Something specific",
 			},
 		},
+		"folder": {
+			classifiers: Classifiers{
+				Lists: []Classifier{
+					{
+						Project:    "Atlas",
+						Subproject: "Launch",
+						Contains: map[string][]string{
+							"folder": {"Projects/Atlas/Launch"},
+						},
+					},
+				},
+			},
+			in: Artifact{
+				Title:      "Launch plan",
+				Attributes: map[string]string{FolderAttribute: "My Drive/Projects/Atlas/Launch/Plans"},
+			},
+			want: Artifact{
+				Title:      "Launch plan",
+				Project:    "Atlas",
+				Subproject: "Launch",
+				Attributes: map[string]string{FolderAttribute: "My Drive/Projects/Atlas/Launch/Plans"},
+			},
+		},
+		"folderroot": {
+			classifiers: Classifiers{
+				Lists: []Classifier{
+					{
+						Project: "Atlas",
+						Contains: map[string][]string{
+							"folder": {"Projects/Atlas"},
+						},
+					},
+				},
+			},
+			in: Artifact{
+				Title:      "Launch plan",
+				Attributes: map[string]string{FolderAttribute: "Projects/Atlas/Launch"},
+			},
+			want: Artifact{
+				Title:      "Launch plan",
+				Project:    "Atlas",
+				Attributes: map[string]string{FolderAttribute: "Projects/Atlas/Launch"},
+			},
+		},
+		"folderprefix": {
+			classifiers: Classifiers{
+				Lists: []Classifier{
+					{
+						Project: "Atlas",
+						Contains: map[string][]string{
+							"folder": {"Projects/Atlas"},
+						},
+					},
+				},
+			},
+			in: Artifact{
+				Title:      "Launch plan",
+				Attributes: map[string]string{FolderAttribute: "My Drive/Projects/Atlas2"},
+			},
+			want: Artifact{
+				Title:      "Launch plan",
+				Attributes: map[string]string{FolderAttribute: "My Drive/Projects/Atlas2"},
+			},
+		},
+		"foldernested": {
+			classifiers: Classifiers{
+				Lists: []Classifier{
+					{
+						Project: "Atlas",
+						Contains: map[string][]string{
+							"folder": {"Projects/Atlas"},
+						},
+					},
+				},
+			},
+			in: Artifact{
+				Title:      "Launch plan",
+				Attributes: map[string]string{FolderAttribute: "My Drive/Old Projects/Atlas"},
+			},
+			want: Artifact{
+				Title:      "Launch plan",
+				Attributes: map[string]string{FolderAttribute: "My Drive/Old Projects/Atlas"},
+			},
+		},
+		"drive": {
+			classifiers: Classifiers{
+				Lists: []Classifier{
+					{
+						Project: "Atlas",
+						Contains: map[string][]string{
+							"drive": {"atlas"},
+						},
+					},
+				},
+			},
+			in: Artifact{
+				Title:      "Launch plan",
+				Attributes: map[string]string{DriveAttribute: "Atlas Team"},
+			},
+			want: Artifact{
+				Title:      "Launch plan",
+				Project:    "Atlas",
+				Attributes: map[string]string{DriveAttribute: "Atlas Team"},
+			},
+		},
+		"nofolder": {
+			classifiers: Classifiers{
+				Lists: []Classifier{
+					{
+						Project: "Atlas",
+						Contains: map[string][]string{
+							"folder": {"Projects/Atlas"},
+						},
+					},
+				},
+			},
+			in: Artifact{
+				Title: "Launch plan",
+			},
+			want: Artifact{
+				Title: "Launch plan",
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -1245,3 +1602,34 @@ func TestUrlMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestArtifactMatches(t *testing.T) {
+	tests := map[string]struct {
+		in   Artifact
+		link string
+		want bool
+	}{
+		"same": {
+			in:   Artifact{Link: "http://example.com/1234"},
+			link: "https://example.com/1234",
+			want: true,
+		},
+		"shortened": {
+			in:   Artifact{Link: "https://docs.google.com/document/d/1234/edit?usp=drivesdk"},
+			link: "https://docs.google.com/document/d/1234",
+			want: true,
+		},
+		"different": {
+			in:   Artifact{Link: "http://example.com/1234"},
+			link: "http://example.com/5678",
+			want: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tc.in.Matches(tc.link)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package artifact
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExtraColumn is the header of the column that holds an artifact's Extra
+// field. It is only written when at least one artifact uses it.
+const ExtraColumn = "Extra"
+
+// Columns returns the headers needed to write the artifacts as rows: the
+// standard Header, then Extra if it is used, then any custom attributes in
+// alphabetical order
+func (a Artifacts) Columns() []string {
+	result := append([]string{}, Header...)
+
+	extra := false
+	attrs := map[string]bool{}
+	for _, art := range a {
+		if art.Extra != "" {
+			extra = true
+		}
+		for k := range art.Attributes {
+			attrs[k] = true
+		}
+	}
+
+	if extra {
+		result = append(result, ExtraColumn)
+	}
+
+	keys := []string{}
+	for k := range attrs {
+		if !IsColumn(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return append(result, keys...)
+}
+
+// IsColumn reports whether a header names one of the fixed artifact fields
+// rather than a custom attribute
+func IsColumn(header string) bool {
+	return column(header) != ""
+}
+
+// column returns the canonical name of a fixed artifact field, or an empty
+// string for a custom attribute
+func column(header string) string {
+	h := uniform(header)
+	for _, v := range append(Header, ExtraColumn) {
+		if h == uniform(v) {
+			return v
+		}
+	}
+	return ""
+}
+
+// Value returns the contents of the named column as a string. Dates are
+// written as 01/02/2006 and are empty when unset.
+func (a Artifact) Value(header string) string {
+	switch column(header) {
+	case "Type":
+		return a.Type
+	case "Project":
+		return a.Project
+	case "Subproject":
+		return a.Subproject
+	case "Title":
+		return a.Title
+	case "Role":
+		return a.Role
+	case "Shipped Date":
+		if a.ShippedDate.IsZero() {
+			return ""
+		}
+		return a.ShippedDate.Format(csvDateFormat)
+	case "Link":
+		return a.Link
+	case ExtraColumn:
+		return a.Extra
+	}
+	return a.Attributes[header]
+}
+
+// Set fills in the named column from a string, the reverse of Value. Headers
+// that aren't fixed fields are stored as custom attributes.
+func (a *Artifact) Set(header, value string) error {
+	switch column(header) {
+	case "Type":
+		a.Type = value
+	case "Project":
+		a.Project = value
+	case "Subproject":
+		a.Subproject = value
+	case "Title":
+		a.Title = value
+	case "Role":
+		a.Role = value
+	case "Shipped Date":
+		if value == "" {
+			a.ShippedDate = time.Time{}
+			return nil
+		}
+		t, err := time.Parse(csvDateFormat, value)
+		if err != nil {
+			return err
+		}
+		a.ShippedDate = t
+	case "Link":
+		a.Link = value
+	case ExtraColumn:
+		a.Extra = value
+	default:
+		header = strings.TrimSpace(header)
+		if header == "" || value == "" {
+			return nil
+		}
+		if a.Attributes == nil {
+			a.Attributes = map[string]string{}
+		}
+		a.Attributes[header] = value
+	}
+	return nil
+}
+
+// Row converts an artifact to a sheet row with the input columns, writing
+// the link as a hyperlink
+func (a Artifact) Row(columns []string) []interface{} {
+	result := []interface{}{}
+	for _, c := range columns {
+		switch column(c) {
+		case "Link":
+			result = append(result, a.Hyperlink())
+		case "Shipped Date":
+			result = append(result, a.ShippedDate.Format(csvDateFormat))
+		default:
+			result = append(result, a.Value(c))
+		}
+	}
+	return result
+}
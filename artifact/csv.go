@@ -0,0 +1,91 @@
+package artifact
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const csvDateFormat = "01/02/2006"
+
+// Header is the column order used when artifacts are written out as rows
+var Header = []string{"Type", "Project", "Subproject", "Title", "Role", "Shipped Date", "Link"}
+
+func (a Artifact) record(columns []string) []string {
+	result := []string{}
+	for _, c := range columns {
+		result = append(result, a.Value(c))
+	}
+	return result
+}
+
+// WriteCSV writes the artifacts as delimited rows, with a header, to w. Use
+// ',' for CSV and '\t' for TSV. Extra and custom attributes follow the
+// standard columns when any artifact has them.
+func (a Artifacts) WriteCSV(w io.Writer, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	columns := a.Columns()
+	if err := cw.Write(columns); err != nil {
+		return fmt.Errorf("could not write header: %w", err)
+	}
+
+	for _, art := range a {
+		if err := cw.Write(art.record(columns)); err != nil {
+			return fmt.Errorf("could not write artifact %s: %w", art.Link, err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads artifacts from delimited rows written by WriteCSV. Columns
+// after the standard ones are read into Extra and custom attributes by header.
+func ReadCSV(r io.Reader, comma rune) (Artifacts, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return Artifacts{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read header: %w", err)
+	}
+
+	if len(header) < len(Header) {
+		return nil, fmt.Errorf("expected at least %d columns, got %d", len(Header), len(header))
+	}
+
+	for i, v := range Header {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), v) {
+			return nil, fmt.Errorf("unexpected column %q, expected %q", header[i], v)
+		}
+	}
+
+	result := Artifacts{}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read row: %w", err)
+		}
+
+		art := Artifact{}
+		for i, v := range rec {
+			if err := art.Set(header[i], v); err != nil {
+				line, _ := cr.FieldPos(i)
+				return nil, fmt.Errorf("could not parse %s on line %d: %w", strings.ToLower(header[i]), line, err)
+			}
+		}
+
+		result = append(result, art)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,165 @@
+package artifact
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtifactsWriteCSV(t *testing.T) {
+	tests := map[string]struct {
+		in    Artifacts
+		comma rune
+		want  string
+	}{
+		"csv": {
+			in: Artifacts{
+				Artifact{
+					Title:       "Title, with comma",
+					Type:        "Type",
+					Link:        "http://example.com",
+					Project:     "Proj",
+					Subproject:  "Sub",
+					Role:        "Role",
+					ShippedDate: time.Date(2023, 8, 21, 12, 0, 0, 0, time.UTC),
+				},
+			},
+			comma: ',',
+			want: "Type,Project,Subproject,Title,Role,Shipped Date,Link\n" +
+				"Type,Proj,Sub,\"Title, with comma\",Role,08/21/2023,http://example.com\n",
+		},
+		"tsv": {
+			in: Artifacts{
+				Artifact{
+					Title: "Title",
+					Link:  "http://example.com",
+				},
+			},
+			comma: '\t',
+			want: "Type\tProject\tSubproject\tTitle\tRole\tShipped Date\tLink\n" +
+				"\t\t\tTitle\t\t\thttp://example.com\n",
+		},
+		"empty": {
+			in:    Artifacts{},
+			comma: ',',
+			want:  "Type,Project,Subproject,Title,Role,Shipped Date,Link\n",
+		},
+		"attributes": {
+			in: Artifacts{
+				Artifact{
+					Title:      "Title",
+					Link:       "http://example.com",
+					Extra:      "Extra",
+					Attributes: map[string]string{"Impact": "High"},
+				},
+			},
+			comma: ',',
+			want: "Type,Project,Subproject,Title,Role,Shipped Date,Link,Extra,Impact\n" +
+				",,,Title,,,http://example.com,Extra,High\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.in.WriteCSV(&buf, tc.comma); err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := map[string]struct {
+		in     string
+		comma  rune
+		want   Artifacts
+		errStr string
+	}{
+		"csv": {
+			in: "Type,Project,Subproject,Title,Role,Shipped Date,Link\n" +
+				"Type,Proj,Sub,\"Title, with comma\",Role,08/21/2023,http://example.com\n",
+			comma: ',',
+			want: Artifacts{
+				Artifact{
+					Title:       "Title, with comma",
+					Type:        "Type",
+					Link:        "http://example.com",
+					Project:     "Proj",
+					Subproject:  "Sub",
+					Role:        "Role",
+					ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		"tsv": {
+			in: "Type\tProject\tSubproject\tTitle\tRole\tShipped Date\tLink\n" +
+				"\t\t\tTitle\t\t\thttp://example.com\n",
+			comma: '\t',
+			want: Artifacts{
+				Artifact{
+					Title: "Title",
+					Link:  "http://example.com",
+				},
+			},
+		},
+		"empty": {
+			in:    "",
+			comma: ',',
+			want:  Artifacts{},
+		},
+		"attributes": {
+			in: "Type,Project,Subproject,Title,Role,Shipped Date,Link,Extra,Impact\n" +
+				",,,Title,,,http://example.com,Extra,High\n",
+			comma: ',',
+			want: Artifacts{
+				Artifact{
+					Title:      "Title",
+					Link:       "http://example.com",
+					Extra:      "Extra",
+					Attributes: map[string]string{"Impact": "High"},
+				},
+			},
+		},
+		"badheader": {
+			in:     "Project,Type,Subproject,Title,Role,Shipped Date,Link\n",
+			comma:  ',',
+			errStr: "unexpected column \"Project\"",
+		},
+		"baddate": {
+			in: "Type,Project,Subproject,Title,Role,Shipped Date,Link\n" +
+				",,,Title,,yesterday,http://example.com\n",
+			comma:  ',',
+			errStr: "could not parse shipped date on line 2",
+		},
+		"shortrow": {
+			in: "Type,Project,Subproject,Title,Role,Shipped Date,Link\n" +
+				"Title,http://example.com\n",
+			comma:  ',',
+			errStr: "wrong number of fields",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ReadCSV(strings.NewReader(tc.in), tc.comma)
+
+			if tc.errStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+					t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package artifact
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// plain strips the custom marshalling from Artifact so the default encoder
+// can be used for every field but ShippedDate
+type plain Artifact
+
+func formatShipped(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func parseShipped(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse shipped date: %w", err)
+	}
+	return t, nil
+}
+
+// MarshalJSON encodes an artifact with its ShippedDate in RFC 3339
+func (a Artifact) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		plain
+		ShippedDate string `json:"shipped_date,omitempty"`
+	}{plain(a), formatShipped(a.ShippedDate)})
+}
+
+// UnmarshalJSON decodes an artifact with its ShippedDate in RFC 3339
+func (a *Artifact) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		*plain
+		ShippedDate string `json:"shipped_date,omitempty"`
+	}{plain: (*plain)(a)}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	shipped, err := parseShipped(raw.ShippedDate)
+	if err != nil {
+		return err
+	}
+	a.ShippedDate = shipped
+
+	return nil
+}
+
+// WriteJSON writes the artifacts to w as a JSON array
+func (a Artifacts) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(a); err != nil {
+		return fmt.Errorf("could not encode artifacts: %w", err)
+	}
+	return nil
+}
+
+// ReadJSON reads artifacts written by WriteJSON
+func ReadJSON(r io.Reader) (Artifacts, error) {
+	result := Artifacts{}
+
+	if err := json.NewDecoder(r).Decode(&result); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not decode artifacts: %w", err)
+	}
+	return result, nil
+}
+
+// WriteJSONLines writes the artifacts to w as one JSON object per line
+func (a Artifacts) WriteJSONLines(w io.Writer) error {
+	enc := json.NewEncoder(w)
+
+	for _, art := range a {
+		if err := enc.Encode(art); err != nil {
+			return fmt.Errorf("could not encode artifact %s: %w", art.Link, err)
+		}
+	}
+	return nil
+}
+
+// ReadJSONLines reads artifacts written by WriteJSONLines. Blank lines are
+// ignored.
+func ReadJSONLines(r io.Reader) (Artifacts, error) {
+	result := Artifacts{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		art := Artifact{}
+		if err := json.Unmarshal(scanner.Bytes(), &art); err != nil {
+			return nil, fmt.Errorf("could not decode artifact on line %d: %w", line, err)
+		}
+		result = append(result, art)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read artifacts: %w", err)
+	}
+	return result, nil
+}
+
+// WriteYAML writes the artifacts to w as a YAML list
+func (a Artifacts) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+
+	if err := enc.Encode(a); err != nil {
+		return fmt.Errorf("could not encode artifacts: %w", err)
+	}
+	return enc.Close()
+}
+
+// ReadYAML reads artifacts written by WriteYAML
+func ReadYAML(r io.Reader) (Artifacts, error) {
+	result := Artifacts{}
+
+	if err := yaml.NewDecoder(r).Decode(&result); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not decode artifacts: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package artifact
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var encodingArtifacts = Artifacts{
+	Artifact{
+		Title:       "Title, with comma",
+		Type:        "Pull Request",
+		Link:        "http://example.com/1",
+		Project:     "Proj",
+		Subproject:  "Sub",
+		Role:        "author",
+		ShippedDate: time.Date(2023, 8, 21, 12, 30, 0, 0, time.UTC),
+	},
+	Artifact{
+		Title: "No date",
+		Link:  "http://example.com/2",
+	},
+}
+
+func TestArtifactsEncoding(t *testing.T) {
+	tests := map[string]struct {
+		golden string
+		write  func(a Artifacts, w io.Writer) error
+		read   func(r io.Reader) (Artifacts, error)
+	}{
+		"json": {
+			golden: "testdata/artifacts.json",
+			write:  Artifacts.WriteJSON,
+			read:   ReadJSON,
+		},
+		"jsonl": {
+			golden: "testdata/artifacts.jsonl",
+			write:  Artifacts.WriteJSONLines,
+			read:   ReadJSONLines,
+		},
+		"yaml": {
+			golden: "testdata/artifacts.yaml",
+			write:  Artifacts.WriteYAML,
+			read:   ReadYAML,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			want, err := os.ReadFile(tc.golden)
+			if err != nil {
+				t.Fatalf("could not read golden file: %s", err)
+			}
+
+			var buf bytes.Buffer
+			if err := tc.write(encodingArtifacts, &buf); err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+			assert.Equal(t, string(want), buf.String())
+
+			got, err := tc.read(bytes.NewReader(want))
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+			assert.Equal(t, encodingArtifacts, got)
+		})
+	}
+}
+
+func TestArtifactsDecodingErrors(t *testing.T) {
+	tests := map[string]struct {
+		in     string
+		read   func(r io.Reader) (Artifacts, error)
+		want   Artifacts
+		errStr string
+	}{
+		"json_empty": {
+			in:   "",
+			read: ReadJSON,
+			want: Artifacts{},
+		},
+		"json_baddate": {
+			in:     `[{"title":"x","shipped_date":"08/21/2023"}]`,
+			read:   ReadJSON,
+			errStr: "could not parse shipped date",
+		},
+		"jsonl_blanklines": {
+			in:   "{\"title\":\"x\"}\n\n{\"title\":\"y\"}\n",
+			read: ReadJSONLines,
+			want: Artifacts{{Title: "x"}, {Title: "y"}},
+		},
+		"jsonl_garbage": {
+			in:     "{\"title\":\"x\"}\nnot json\n",
+			read:   ReadJSONLines,
+			errStr: "on line 2",
+		},
+		"yaml_empty": {
+			in:   "",
+			read: ReadYAML,
+			want: Artifacts{},
+		},
+		"yaml_garbage": {
+			in:     "- title: [x\n",
+			read:   ReadYAML,
+			errStr: "could not decode artifacts",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.read(strings.NewReader(tc.in))
+
+			if tc.errStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+					t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -1,14 +1,24 @@
 package drive
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/tpryan/work/artifact"
-	"google.golang.org/api/drive/v2"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 )
 
+// DefaultPageSize is the number of files asked for in each page of results,
+// the most drive allows
+const DefaultPageSize = 1000
+
+// fileFields are the only file fields requested from drive, enough to build
+// artifacts and tell which role the user played
+const fileFields = "id, name, mimeType, webViewLink, driveId, parents, labelInfo, trashed, owners(emailAddress, me), createdTime, modifiedTime, modifiedByMeTime, lastModifyingUser(emailAddress, me)"
+
 // MimeList is a collection of mimetypes
 type MimeList []string
 
@@ -29,48 +39,37 @@ func (m MimeList) String() string {
 // DriveFiles is a collection of files returned from a Google Drive query
 type DriveFiles []*drive.File
 
+// Roles given to drive artifacts
+const (
+	RoleAuthor   = "Author"
+	RoleEditor   = "editor"
+	RoleReviewer = "reviewer"
+)
+
 // Artifacts returns a collection of artifacts from a collection of drive files
 func (d DriveFiles) Artifacts() artifact.Artifacts {
+	return d.ArtifactsAs(RoleAuthor)
+}
+
+// ArtifactsAs returns a collection of artifacts from a collection of drive
+// files with the given Role
+func (d DriveFiles) ArtifactsAs(role string) artifact.Artifacts {
 
 	arts := artifact.Artifacts{}
 
 	for _, v := range d {
 
-		shipped, err := time.Parse("2006-01-02T15:04:05.999Z", v.CreatedDate)
+		shipped, err := time.Parse(time.RFC3339, v.CreatedTime)
 		if err != nil {
 			shipped = time.Time{}
 		}
 
 		a := artifact.Artifact{
-			Title:       v.Title,
-			Link:        v.AlternateLink,
+			Title:       v.Name,
+			Link:        v.WebViewLink,
 			ShippedDate: shipped,
-			Role:        "Author",
-		}
-
-		// TODO: do at a higher level - now built into
-		// if strings.Contains(a.Title, "Copy ") {
-		// 	continue
-		// }
-
-		if strings.Contains(strings.ToLower(a.Title), strings.ToLower("prd")) ||
-			strings.Contains(strings.ToLower(a.Title), strings.ToLower("tdd")) {
-			a.Type = "Design Doc"
-		}
-
-		typeMap := map[string]string{
-			"application/vnd.google-apps.spreadsheet":  "Sheet",
-			"application/vnd.google-apps.document":     "Doc",
-			"application/vnd.google-apps.presentation": "Slides",
-			"application/vnd.google.colaboratory.corp": "Colab",
-			"application/vnd.google-apps.form":         "Form",
-		}
-
-		if a.Type == "" {
-			a.Type = "File"
-			if t, ok := typeMap[v.MimeType]; ok {
-				a.Type = t
-			}
+			Role:        role,
+			Type:        DefaultRules.Type(v, ""),
 		}
 
 		arts = append(arts, a)
@@ -79,24 +78,45 @@ func (d DriveFiles) Artifacts() artifact.Artifacts {
 	return arts
 }
 
-// Search  returns results from Google Drive as artifacts
+// Search  returns results from Google Drive as artifacts, including files in
+// shared drives
 func Search(q string, svc *drive.Service) (artifact.Artifacts, error) {
+	call := svc.Files.List().
+		Q(q).
+		Corpora("allDrives").
+		IncludeItemsFromAllDrives(true).
+		SupportsAllDrives(true)
+
+	files, err := list(context.Background(), call, DefaultPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return files.Artifacts(), nil
+}
+
+// list pages through every file a list call returns, asking only for the
+// fields artifacts need
+func list(ctx context.Context, call *drive.FilesListCall, pageSize int64) (DriveFiles, error) {
 
 	files := DriveFiles{}
 	var pageToken string
 
+	call = call.
+		PageSize(pageSize).
+		Fields(googleapi.Field(fmt.Sprintf("nextPageToken, files(%s)", fileFields)))
+
 	for {
-		r, err := svc.Files.List().PageToken(pageToken).Q(q).Do()
+		r, err := call.PageToken(pageToken).Context(ctx).Do()
 		if err != nil {
 			return nil, fmt.Errorf("drive files list failed: %s", err)
 		}
 
-		files = append(files, r.Items...)
+		files = append(files, r.Files...)
 
 		pageToken = r.NextPageToken
 		if pageToken == "" {
 			break
 		}
 	}
-	return files.Artifacts(), nil
+	return files, nil
 }
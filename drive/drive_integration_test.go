@@ -10,7 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/tpryan/work/artifact"
 	"github.com/tpryan/work/option"
-	"google.golang.org/api/drive/v2"
+	"google.golang.org/api/drive/v3"
 )
 
 var credsTestPath = "../testdata/test-creds.json"
@@ -45,7 +45,7 @@ func TestDriveSearch(t *testing.T) {
 	}{
 		"basic": {
 
-			q: "title contains 'Deploystack Performance Metrics' AND mimeType='application/vnd.google-apps.spreadsheet'",
+			q: "name contains 'Deploystack Performance Metrics' AND mimeType='application/vnd.google-apps.spreadsheet'",
 			want: artifact.Artifacts{
 				artifact.Artifact{
 					Title:       "Deploystack Performance Metrics",
@@ -61,7 +61,7 @@ func TestDriveSearch(t *testing.T) {
 		},
 		"error": {
 
-			q:      "title contains 'Deploystack Performance Metrics",
+			q:      "name contains 'Deploystack Performance Metrics",
 			want:   artifact.Artifacts{},
 			errStr: "Invalid query, invalid",
 		},
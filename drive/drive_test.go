@@ -1,12 +1,20 @@
 package drive
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tpryan/work"
 	"github.com/tpryan/work/artifact"
-	"google.golang.org/api/drive/v2"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
 )
 
 func TestMimeListString(t *testing.T) {
@@ -43,10 +51,10 @@ func TestDriveArtifacts(t *testing.T) {
 		"sheet": {
 			in: DriveFiles{
 				&drive.File{
-					Title:         "title",
-					AlternateLink: "https://example.com",
-					CreatedDate:   "2023-08-24T09:00:00.000Z",
-					MimeType:      "application/vnd.google-apps.spreadsheet",
+					Name:        "title",
+					WebViewLink: "https://example.com",
+					CreatedTime: "2023-08-24T09:00:00.000Z",
+					MimeType:    "application/vnd.google-apps.spreadsheet",
 				},
 			},
 			want: artifact.Artifacts{
@@ -62,10 +70,10 @@ func TestDriveArtifacts(t *testing.T) {
 		"doc": {
 			in: DriveFiles{
 				&drive.File{
-					Title:         "title",
-					AlternateLink: "https://example.com",
-					CreatedDate:   "2023-08-24T09:00:00.000Z",
-					MimeType:      "application/vnd.google-apps.document",
+					Name:        "title",
+					WebViewLink: "https://example.com",
+					CreatedTime: "2023-08-24T09:00:00.000Z",
+					MimeType:    "application/vnd.google-apps.document",
 				},
 			},
 			want: artifact.Artifacts{
@@ -81,10 +89,10 @@ func TestDriveArtifacts(t *testing.T) {
 		"slides": {
 			in: DriveFiles{
 				&drive.File{
-					Title:         "title",
-					AlternateLink: "https://example.com",
-					CreatedDate:   "2023-08-24T09:00:00.000Z",
-					MimeType:      "application/vnd.google-apps.presentation",
+					Name:        "title",
+					WebViewLink: "https://example.com",
+					CreatedTime: "2023-08-24T09:00:00.000Z",
+					MimeType:    "application/vnd.google-apps.presentation",
 				},
 			},
 			want: artifact.Artifacts{
@@ -100,10 +108,10 @@ func TestDriveArtifacts(t *testing.T) {
 		"file": {
 			in: DriveFiles{
 				&drive.File{
-					Title:         "title",
-					AlternateLink: "https://example.com",
-					CreatedDate:   "2023-08-24T09:00:00.000Z",
-					MimeType:      "application/vnd.adobe.pdf",
+					Name:        "title",
+					WebViewLink: "https://example.com",
+					CreatedTime: "2023-08-24T09:00:00.000Z",
+					MimeType:    "application/vnd.adobe.pdf",
 				},
 			},
 			want: artifact.Artifacts{
@@ -119,10 +127,10 @@ func TestDriveArtifacts(t *testing.T) {
 		"badtime": {
 			in: DriveFiles{
 				&drive.File{
-					Title:         "title",
-					AlternateLink: "https://example.com",
-					CreatedDate:   "BADTIMEFORMAT",
-					MimeType:      "application/vnd.google-apps.spreadsheet",
+					Name:        "title",
+					WebViewLink: "https://example.com",
+					CreatedTime: "BADTIMEFORMAT",
+					MimeType:    "application/vnd.google-apps.spreadsheet",
 				},
 			},
 			want: artifact.Artifacts{
@@ -139,10 +147,10 @@ func TestDriveArtifacts(t *testing.T) {
 		"PRD": {
 			in: DriveFiles{
 				&drive.File{
-					Title:         "title prd",
-					AlternateLink: "https://example.com",
-					CreatedDate:   "BADTIMEFORMAT",
-					MimeType:      "application/vnd.google-apps.document",
+					Name:        "title prd",
+					WebViewLink: "https://example.com",
+					CreatedTime: "BADTIMEFORMAT",
+					MimeType:    "application/vnd.google-apps.document",
 				},
 			},
 			want: artifact.Artifacts{
@@ -164,3 +172,703 @@ func TestDriveArtifacts(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceQuery(t *testing.T) {
+	mimes := DefaultMimeList.String()
+
+	tests := map[string]struct {
+		in   work.DriveConfig
+		want string
+	}{
+		"default": {
+			in:   work.DriveConfig{},
+			want: "'me' in owners and (" + mimes + ")",
+		},
+		"owner": {
+			in:   work.DriveConfig{Owner: "someone@example.com"},
+			want: "'someone@example.com' in owners and (" + mimes + ")",
+		},
+		"shared": {
+			in:   work.DriveConfig{Owner: "someone@example.com", Drives: []string{"drive1"}},
+			want: "(" + mimes + ")",
+		},
+		"everything": {
+			in: work.DriveConfig{
+				Owner:   "o'brien@example.com",
+				Mimes:   []string{"application/vnd.google-apps.document"},
+				Folders: []string{"folder1", "folder2"},
+				Clauses: []string{"modifiedTime > '2023-01-01'", "starred = true"},
+			},
+			want: "'o\\'brien@example.com' in owners" +
+				" and (mimeType='application/vnd.google-apps.document')" +
+				" and ('folder1' in parents or 'folder2' in parents)" +
+				" and (modifiedTime > '2023-01-01')" +
+				" and (starred = true)",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewSource(nil, work.SourceConfig{Name: "Drive", Drive: tc.in})
+			if err != nil {
+				t.Fatalf("unable to create source: %s", err)
+			}
+			assert.Equal(t, tc.want, s.Query())
+		})
+	}
+}
+
+func TestSourceCollect(t *testing.T) {
+	requests := []url.Values{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/revisions"):
+			fmt.Fprint(w, `{"revisions":[{"lastModifyingUser":{"me":true}}]}`)
+			return
+		case strings.Contains(r.URL.Path, "/drives/"):
+			fmt.Fprint(w, `{"name":"Team"}`)
+			return
+		}
+
+		requests = append(requests, r.URL.Query())
+		drive := r.URL.Query().Get("driveId")
+		if r.URL.Query().Get("pageToken") == "" {
+			fmt.Fprintf(w, `{"files":[{"id":"1","name":"One","webViewLink":"https://docs.google.com/1","mimeType":"application/vnd.google-apps.document","driveId":%q,"owners":[{"me":true}],"createdTime":"2023-08-21T00:00:00.000Z","modifiedByMeTime":"2023-08-21T00:00:00.000Z"}],"nextPageToken":"next"}`, drive)
+			return
+		}
+		fmt.Fprintf(w, `{"files":[{"id":"2","name":"Two","webViewLink":"https://docs.google.com/2","mimeType":"application/vnd.google-apps.spreadsheet","driveId":%q,"owners":[{"me":true}],"createdTime":"2023-08-22T00:00:00.000Z","modifiedByMeTime":"2023-08-22T00:00:00.000Z"}]}`, drive)
+	}))
+	defer srv.Close()
+
+	svc, err := drive.NewService(context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("unable to create fake drive service: %s", err)
+	}
+
+	mimes := "(" + DefaultMimeList.String() + ")"
+
+	tests := map[string]struct {
+		in          work.DriveConfig
+		wantTitles  []string
+		wantQuery   string
+		wantDrives  []string
+		wantCorpora []string
+		wantSize    string
+	}{
+		"mydrive": {
+			in:          work.DriveConfig{Owner: "someone@example.com", Roles: []string{"author"}},
+			wantTitles:  []string{"One", "Two"},
+			wantQuery:   "'someone@example.com' in owners and " + mimes,
+			wantDrives:  []string{"", ""},
+			wantCorpora: []string{"", ""},
+			wantSize:    "1000",
+		},
+		"shared": {
+			in:          work.DriveConfig{Drives: []string{"drive1", "drive2"}, Roles: []string{"author"}},
+			wantTitles:  []string{"One", "Two", "One", "Two", "One", "Two"},
+			wantQuery:   mimes,
+			wantDrives:  []string{"", "", "drive1", "drive1", "drive2", "drive2"},
+			wantCorpora: []string{"user", "user", "drive", "drive", "drive", "drive"},
+			wantSize:    "1000",
+		},
+		"all": {
+			in:          work.DriveConfig{Drives: []string{"drive1", "All"}, Roles: []string{"author"}},
+			wantTitles:  []string{"One", "Two"},
+			wantQuery:   mimes,
+			wantDrives:  []string{"", ""},
+			wantCorpora: []string{"allDrives", "allDrives"},
+			wantSize:    "1000",
+		},
+		"pagesize": {
+			in:          work.DriveConfig{Roles: []string{"author"}, PageSize: 50},
+			wantTitles:  []string{"One", "Two"},
+			wantQuery:   "'me' in owners and " + mimes,
+			wantDrives:  []string{"", ""},
+			wantCorpora: []string{"", ""},
+			wantSize:    "50",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests = []url.Values{}
+			s, err := NewSource(svc, work.SourceConfig{Name: "Drive", Drive: tc.in})
+			if err != nil {
+				t.Fatalf("unable to create source: %s", err)
+			}
+
+			got, err := s.Collect(context.Background(), work.Criteria{})
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			titles := []string{}
+			for _, art := range got {
+				titles = append(titles, art.Title)
+			}
+			assert.Equal(t, tc.wantTitles, titles)
+
+			drives := []string{}
+			corpora := []string{}
+			for _, r := range requests {
+				assert.Equal(t, tc.wantQuery, r.Get("q"))
+				assert.Equal(t, tc.wantSize, r.Get("pageSize"))
+				corpora = append(corpora, r.Get("corpora"))
+				assert.True(t, strings.HasPrefix(r.Get("fields"), "nextPageToken, files("), "expected a fields mask, got %q", r.Get("fields"))
+				drives = append(drives, r.Get("driveId"))
+			}
+			assert.Equal(t, tc.wantDrives, drives)
+			assert.Equal(t, tc.wantCorpora, corpora)
+		})
+	}
+}
+
+func TestSourceSharedDrives(t *testing.T) {
+	queries := []string{}
+	lookups := map[string]int{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/files/made/revisions"):
+			lookups["made"]++
+			fmt.Fprint(w, `{"revisions":[{"lastModifyingUser":{"emailAddress":"me@example.com"}}]}`)
+		case strings.HasSuffix(r.URL.Path, "/files/edited/revisions"):
+			lookups["edited"]++
+			fmt.Fprint(w, `{"revisions":[{"lastModifyingUser":{"emailAddress":"other@example.com"}}]}`)
+		case strings.HasSuffix(r.URL.Path, "/comments"):
+			fmt.Fprint(w, `{"comments":[]}`)
+		case strings.HasSuffix(r.URL.Path, "/drives/team"):
+			fmt.Fprint(w, `{"name":"Team"}`)
+		case strings.HasSuffix(r.URL.Path, "/files"):
+			queries = append(queries, r.URL.Query().Get("q"))
+			fmt.Fprint(w, `{"files":[
+				{"id":"made","name":"Made","driveId":"team","modifiedTime":"2023-08-02T00:00:00.000Z","modifiedByMeTime":"2023-08-01T00:00:00.000Z"},
+				{"id":"edited","name":"Edited","driveId":"team","modifiedTime":"2023-08-03T00:00:00.000Z","modifiedByMeTime":"2023-08-03T00:00:00.000Z"},
+				{"id":"untouched","name":"Untouched","driveId":"team","modifiedTime":"2023-08-04T00:00:00.000Z"},
+				{"id":"mine","name":"Mine","modifiedTime":"2023-08-05T00:00:00.000Z","owners":[{"emailAddress":"me@example.com"}]},
+				{"id":"theirs","name":"Theirs","modifiedTime":"2023-08-06T00:00:00.000Z","owners":[{"emailAddress":"other@example.com"}]}
+			]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := drive.NewService(context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("unable to create fake drive service: %s", err)
+	}
+
+	s, err := NewSource(svc, work.SourceConfig{Name: "Drive", Drive: work.DriveConfig{
+		Owner:  "me@example.com",
+		Drives: []string{AllDrives},
+		Roles:  []string{"author", "editor"},
+	}})
+	if err != nil {
+		t.Fatalf("unable to create source: %s", err)
+	}
+
+	got, err := s.Collect(context.Background(), work.Criteria{})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	roles := map[string]string{}
+	for _, art := range got {
+		roles[art.Title] = art.Role
+	}
+	assert.Equal(t, map[string]string{"Made": RoleAuthor, "Mine": RoleAuthor, "Edited": RoleEditor}, roles)
+
+	for _, q := range queries {
+		assert.NotContains(t, q, "owners")
+	}
+	assert.Equal(t, map[string]int{"made": 1, "edited": 1}, lookups, "only files the user modified should be looked up, once each")
+}
+
+func TestSourceContributions(t *testing.T) {
+	shared := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/files/edited/comments"):
+			fmt.Fprint(w, `{"comments":[]}`)
+		case strings.HasSuffix(r.URL.Path, "/files/commented/comments"):
+			if r.URL.Query().Get("pageToken") == "" {
+				fmt.Fprint(w, `{"comments":[{"author":{"emailAddress":"other@example.com"},"createdTime":"2023-08-01T00:00:00.000Z","replies":[{"author":{"emailAddress":"me@example.com"},"createdTime":"2023-08-03T00:00:00.000Z"}]}],"nextPageToken":"next"}`)
+				return
+			}
+			fmt.Fprint(w, `{"comments":[{"author":{"emailAddress":"me@example.com"},"createdTime":"2023-08-02T00:00:00.000Z"}]}`)
+		case strings.HasSuffix(r.URL.Path, "/files/untouched/comments"):
+			fmt.Fprint(w, `{"comments":[{"author":{"emailAddress":"other@example.com"},"createdTime":"2023-08-01T00:00:00.000Z"},{"author":{"emailAddress":"me@example.com"},"createdTime":"2023-08-02T00:00:00.000Z","deleted":true}]}`)
+		case strings.HasSuffix(r.URL.Path, "/files"):
+			if strings.HasPrefix(r.URL.Query().Get("q"), "not ") {
+				shared = r.URL.Query().Get("q")
+				fmt.Fprint(w, `{"files":[
+					{"id":"edited","name":"Edited","webViewLink":"https://docs.google.com/edited","modifiedByMeTime":"2023-08-05T00:00:00.000Z"},
+					{"id":"commented","name":"Commented","webViewLink":"https://docs.google.com/commented","lastModifyingUser":{"emailAddress":"other@example.com"},"modifiedTime":"2023-08-06T00:00:00.000Z"},
+					{"id":"untouched","name":"Untouched","webViewLink":"https://docs.google.com/untouched"}
+				]}`)
+				return
+			}
+			fmt.Fprint(w, `{"files":[{"id":"owned","name":"Owned","webViewLink":"https://docs.google.com/owned","createdTime":"2023-08-04T00:00:00.000Z"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := drive.NewService(context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("unable to create fake drive service: %s", err)
+	}
+
+	owned := artifact.Artifact{Title: "Owned", Link: "https://docs.google.com/owned", Role: RoleAuthor, ShippedDate: time.Date(2023, 8, 4, 0, 0, 0, 0, time.UTC)}
+	edited := artifact.Artifact{Title: "Edited", Link: "https://docs.google.com/edited", Role: RoleEditor, ShippedDate: time.Date(2023, 8, 5, 0, 0, 0, 0, time.UTC)}
+	commented := artifact.Artifact{Title: "Commented", Link: "https://docs.google.com/commented", Role: RoleReviewer, ShippedDate: time.Date(2023, 8, 3, 0, 0, 0, 0, time.UTC)}
+
+	tests := map[string]struct {
+		in     []string
+		want   []artifact.Artifact
+		window string
+	}{
+		"default": {
+			want:   []artifact.Artifact{owned, edited, commented},
+			window: "(modifiedTime > '2023-01-01T00:00:00Z' or viewedByMeTime > '2023-01-01T00:00:00Z')",
+		},
+		"editor": {
+			in:     []string{"editor"},
+			want:   []artifact.Artifact{edited},
+			window: "modifiedTime > '2023-01-01T00:00:00Z'",
+		},
+		"reviewer": {
+			in:     []string{"Reviewer"},
+			want:   []artifact.Artifact{commented},
+			window: "(modifiedTime > '2023-01-01T00:00:00Z' or viewedByMeTime > '2023-01-01T00:00:00Z')",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewSource(svc, work.SourceConfig{Name: "Drive", Drive: work.DriveConfig{Owner: "me@example.com", Roles: tc.in}})
+			if err != nil {
+				t.Fatalf("unable to create source: %s", err)
+			}
+
+			shared = ""
+			got, err := s.Collect(context.Background(), work.Criteria{Start: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)})
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.True(t, strings.HasSuffix(shared, " and "+tc.window), "query %q is missing %q", shared, tc.window)
+			assert.Len(t, got, len(tc.want))
+			for i, want := range tc.want {
+				if i >= len(got) {
+					break
+				}
+				assert.Equal(t, want.Title, got[i].Title)
+				assert.Equal(t, want.Role, got[i].Role)
+				assert.Equal(t, want.ShippedDate, got[i].ShippedDate.UTC())
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		criteria work.Criteria
+		since    []string
+		want     []string
+	}{
+		"open": {
+			since: []string{"createdTime"},
+			want:  []string{},
+		},
+		"created": {
+			criteria: work.Criteria{Start: start, End: end},
+			since:    []string{"createdTime"},
+			want:     []string{"createdTime > '2023-01-01T00:00:00Z'", "createdTime < '2024-01-01T00:00:00Z'"},
+		},
+		"modified": {
+			criteria: work.Criteria{Start: start, End: end},
+			since:    []string{"modifiedTime"},
+			want:     []string{"modifiedTime > '2023-01-01T00:00:00Z'", "createdTime < '2024-01-01T00:00:00Z'"},
+		},
+		"endonly": {
+			criteria: work.Criteria{Start: start, End: end},
+			want:     []string{"createdTime < '2024-01-01T00:00:00Z'"},
+		},
+		"either": {
+			criteria: work.Criteria{Start: start},
+			since:    []string{"modifiedTime", "viewedByMeTime"},
+			want:     []string{"(modifiedTime > '2023-01-01T00:00:00Z' or viewedByMeTime > '2023-01-01T00:00:00Z')"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := window(tc.criteria, tc.since...)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSourceShipped(t *testing.T) {
+	queries := []string{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/files/design/comments"):
+			fmt.Fprint(w, `{"comments":[
+				{"content":"Looks good","createdTime":"2023-03-01T00:00:00.000Z"},
+				{"content":"Approved!","createdTime":"2023-04-01T00:00:00.000Z"},
+				{"content":"final","createdTime":"2023-02-01T00:00:00.000Z","deleted":true}
+			]}`)
+		case strings.HasSuffix(r.URL.Path, "/files/design/revisions"):
+			fmt.Fprint(w, `{"revisions":[
+				{"modifiedTime":"2021-06-01T00:00:00.000Z"},
+				{"modifiedTime":"2023-04-03T00:00:00.000Z","published":true},
+				{"modifiedTime":"2023-04-02T00:00:00.000Z"}
+			]}`)
+		case strings.HasSuffix(r.URL.Path, "/comments"):
+			fmt.Fprint(w, `{"comments":[]}`)
+		case strings.HasSuffix(r.URL.Path, "/revisions"):
+			fmt.Fprint(w, `{"revisions":[]}`)
+		case strings.HasSuffix(r.URL.Path, "/files"):
+			queries = append(queries, r.URL.Query().Get("q"))
+			fmt.Fprint(w, `{"files":[
+				{"id":"design","name":"Design","createdTime":"2021-05-01T00:00:00.000Z","modifiedByMeTime":"2023-05-01T00:00:00.000Z"},
+				{"id":"notes","name":"Notes","createdTime":"2021-05-02T00:00:00.000Z"}
+			]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := drive.NewService(context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("unable to create fake drive service: %s", err)
+	}
+
+	criteria := work.Criteria{
+		Start: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	created := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	notes := time.Date(2021, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		in        string
+		want      []time.Time
+		wantQuery string
+		errStr    string
+	}{
+		"default": {
+			want:      []time.Time{created, notes},
+			wantQuery: "createdTime > '2023-01-01T00:00:00Z' and createdTime < '2024-01-01T00:00:00Z'",
+		},
+		"modified": {
+			in:        ShippedModified,
+			want:      []time.Time{time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), notes},
+			wantQuery: "modifiedTime > '2023-01-01T00:00:00Z' and createdTime < '2024-01-01T00:00:00Z'",
+		},
+		"marker": {
+			in:        ShippedMarker,
+			want:      []time.Time{time.Date(2023, 4, 2, 0, 0, 0, 0, time.UTC), notes},
+			wantQuery: ") and createdTime < '2024-01-01T00:00:00Z'",
+		},
+		"published": {
+			in:        ShippedPublished,
+			want:      []time.Time{time.Date(2023, 4, 3, 0, 0, 0, 0, time.UTC), notes},
+			wantQuery: "modifiedTime > '2023-01-01T00:00:00Z' and createdTime < '2024-01-01T00:00:00Z'",
+		},
+		"unknown": {
+			in:     "approved",
+			errStr: "unknown shipped policy",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			queries = []string{}
+			s, err := NewSource(svc, work.SourceConfig{Name: "Drive", Drive: work.DriveConfig{Roles: []string{"author"}, Shipped: tc.in}})
+			if err != nil {
+				t.Fatalf("unable to create source: %s", err)
+			}
+
+			got, err := s.Collect(context.Background(), criteria)
+			if tc.errStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+					t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			dates := []time.Time{}
+			for _, art := range got {
+				dates = append(dates, art.ShippedDate.UTC())
+			}
+			assert.Equal(t, tc.want, dates)
+
+			for _, q := range queries {
+				assert.True(t, strings.HasSuffix(q, tc.wantQuery), "expected query ending %q, got %q", tc.wantQuery, q)
+			}
+		})
+	}
+}
+
+func TestRulesType(t *testing.T) {
+	rules, err := NewRules([]work.DriveTypeRule{
+		{Type: "RFC", Title: `^rfc\b`},
+		{Type: "Postmortem", Title: "postmortem", Mime: "application/vnd.google-apps.document"},
+		{Type: "One-pager", Folder: "/one-pagers$"},
+		{Type: "Launch", Label: "launch-label"},
+	})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	tests := map[string]struct {
+		in   *drive.File
+		path string
+		want string
+	}{
+		"title": {
+			in:   &drive.File{Name: "RFC: New storage", MimeType: "application/vnd.google-apps.document"},
+			want: "RFC",
+		},
+		"titleandmime": {
+			in:   &drive.File{Name: "Outage postmortem", MimeType: "application/vnd.google-apps.document"},
+			want: "Postmortem",
+		},
+		"mimemismatch": {
+			in:   &drive.File{Name: "Outage postmortem", MimeType: "application/vnd.google-apps.spreadsheet"},
+			want: "Sheet",
+		},
+		"folder": {
+			in:   &drive.File{Name: "Storage", MimeType: "application/vnd.google-apps.document"},
+			path: "My Drive/Team/One-Pagers",
+			want: "One-pager",
+		},
+		"label": {
+			in:   &drive.File{Name: "Storage", LabelInfo: &drive.FileLabelInfo{Labels: []*drive.Label{{Id: "launch-label"}}}},
+			want: "Launch",
+		},
+		"default": {
+			in:   &drive.File{Name: "Storage TDD", MimeType: "application/vnd.google-apps.document"},
+			want: "Design Doc",
+		},
+		"fallback": {
+			in:   &drive.File{Name: "Storage", MimeType: "application/pdf"},
+			want: "File",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := rules.Type(tc.in, tc.path)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewRulesErrors(t *testing.T) {
+	tests := map[string]struct {
+		in     []work.DriveTypeRule
+		errStr string
+	}{
+		"notype": {
+			in:     []work.DriveTypeRule{{Title: "rfc"}},
+			errStr: "type rule 1 has no type",
+		},
+		"badtitle": {
+			in:     []work.DriveTypeRule{{Type: "RFC", Title: "rfc("}},
+			errStr: "type rule 1 has a bad title pattern",
+		},
+		"badfolder": {
+			in:     []work.DriveTypeRule{{Type: "RFC"}, {Type: "RFC", Folder: "["}},
+			errStr: "type rule 2 has a bad folder pattern",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewRules(tc.in)
+			if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+				t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+			}
+		})
+	}
+}
+
+func TestSourceFolders(t *testing.T) {
+	lookups := 0
+	labels := ""
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/files/pagers"):
+			lookups++
+			fmt.Fprint(w, `{"name":"One-pagers","parents":["root"]}`)
+		case strings.HasSuffix(r.URL.Path, "/files/root"):
+			lookups++
+			fmt.Fprint(w, `{"name":"My Drive"}`)
+		case strings.HasSuffix(r.URL.Path, "/files/team"):
+			lookups++
+			fmt.Fprint(w, `{"name":"Drive"}`)
+		case strings.HasSuffix(r.URL.Path, "/drives/team"):
+			lookups++
+			fmt.Fprint(w, `{"name":"Atlas Team"}`)
+		case strings.HasSuffix(r.URL.Path, "/files"):
+			labels = r.URL.Query().Get("includeLabels")
+			fmt.Fprint(w, `{"files":[
+				{"id":"a","name":"Storage","mimeType":"application/vnd.google-apps.document","parents":["pagers"]},
+				{"id":"b","name":"Search","mimeType":"application/vnd.google-apps.document","parents":["pagers"]},
+				{"id":"c","name":"Budget","mimeType":"application/vnd.google-apps.spreadsheet","parents":["root"]},
+				{"id":"d","name":"Roadmap","mimeType":"application/vnd.google-apps.presentation","parents":["team"],"driveId":"team"},
+				{"id":"e","name":"Launch","mimeType":"application/vnd.google-apps.presentation","parents":["team"],"driveId":"team"}
+			]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := drive.NewService(context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("unable to create fake drive service: %s", err)
+	}
+
+	s, err := NewSource(svc, work.SourceConfig{Name: "Drive", Drive: work.DriveConfig{
+		Roles: []string{"author"},
+		Types: []work.DriveTypeRule{
+			{Type: "One-pager", Folder: "^My Drive/One-pagers$"},
+			{Type: "Launch", Label: "launch-label"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("unable to create source: %s", err)
+	}
+
+	got, err := s.Collect(context.Background(), work.Criteria{})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	types := []string{}
+	folders := []string{}
+	drives := []string{}
+	for _, art := range got {
+		types = append(types, art.Type)
+		folders = append(folders, art.Attributes[artifact.FolderAttribute])
+		drives = append(drives, art.Attributes[artifact.DriveAttribute])
+	}
+	assert.Equal(t, []string{"One-pager", "One-pager", "Sheet", "Slides", "Slides"}, types)
+	assert.Equal(t, []string{"My Drive/One-pagers", "My Drive/One-pagers", "My Drive", "Drive", "Drive"}, folders)
+	assert.Equal(t, []string{"", "", "", "Atlas Team", "Atlas Team"}, drives)
+	assert.Equal(t, 4, lookups, "each folder and drive should be looked up once")
+	assert.Equal(t, "launch-label", labels)
+}
+
+func TestSourceNoise(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"files":[
+			{"id":"1","name":"Design","createdTime":"2023-01-01T00:00:00.000Z","modifiedTime":"2023-02-01T00:00:00.000Z"},
+			{"id":"2","name":"Copy of Design","createdTime":"2023-01-01T00:00:00.000Z","modifiedTime":"2023-02-01T00:00:00.000Z"},
+			{"id":"3","name":"Untitled document","createdTime":"2023-01-01T00:00:00.000Z","modifiedTime":"2023-02-01T00:00:00.000Z"},
+			{"id":"4","name":"Design Doc Template","createdTime":"2023-01-01T00:00:00.000Z","modifiedTime":"2023-02-01T00:00:00.000Z"},
+			{"id":"5","name":"Notes","createdTime":"2023-01-01T00:00:00.000Z","modifiedTime":"2023-01-01T00:00:00.000Z"},
+			{"id":"6","name":"Old","createdTime":"2023-01-01T00:00:00.000Z","modifiedTime":"2023-02-01T00:00:00.000Z","trashed":true},
+			{"id":"7","name":"[Template] PRD","createdTime":"2023-01-01T00:00:00.000Z","modifiedTime":"2023-02-01T00:00:00.000Z"},
+			{"id":"8","name":"Go templates redesign","createdTime":"2023-01-01T00:00:00.000Z","modifiedTime":"2023-02-01T00:00:00.000Z"}
+		]}`)
+	}))
+	defer srv.Close()
+
+	svc, err := drive.NewService(context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("unable to create fake drive service: %s", err)
+	}
+
+	tests := map[string]struct {
+		in          []string
+		want        []string
+		wantDropped map[string]int
+		errStr      string
+	}{
+		"default": {
+			want: []string{"Design", "Go templates redesign"},
+			wantDropped: map[string]int{
+				NoiseCopies:    1,
+				NoiseUntitled:  1,
+				NoiseTemplates: 2,
+				NoiseUnedited:  1,
+				NoiseTrashed:   1,
+			},
+		},
+		"some": {
+			in:          []string{"Copies", "trashed"},
+			want:        []string{"Design", "Untitled document", "Design Doc Template", "Notes", "[Template] PRD", "Go templates redesign"},
+			wantDropped: map[string]int{NoiseCopies: 1, NoiseTrashed: 1},
+		},
+		"templatesany": {
+			in:          []string{"templates-any"},
+			want:        []string{"Design", "Copy of Design", "Untitled document", "Notes", "Old"},
+			wantDropped: map[string]int{NoiseTemplatesAny: 3},
+		},
+		"none": {
+			in:          []string{"none"},
+			want:        []string{"Design", "Copy of Design", "Untitled document", "Design Doc Template", "Notes", "Old", "[Template] PRD", "Go templates redesign"},
+			wantDropped: map[string]int{},
+		},
+		"unknown": {
+			in:     []string{"drafts"},
+			errStr: "unknown noise filter \"drafts\"",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewSource(svc, work.SourceConfig{Name: "Drive", Drive: work.DriveConfig{Roles: []string{"author"}, Noise: tc.in}})
+			if tc.errStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+					t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to create source: %s", err)
+			}
+
+			got, err := s.Collect(context.Background(), work.Criteria{})
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			titles := []string{}
+			for _, art := range got {
+				titles = append(titles, art.Title)
+			}
+			assert.Equal(t, tc.want, titles)
+			assert.Equal(t, tc.wantDropped, s.dropped)
+		})
+	}
+}
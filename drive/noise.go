@@ -0,0 +1,113 @@
+package drive
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/log"
+	"google.golang.org/api/drive/v3"
+)
+
+// Noise filters that drop files that aren't real work
+const (
+	// NoiseCopies drops files named "Copy of ..."
+	NoiseCopies = "copies"
+	// NoiseUntitled drops files that were never named
+	NoiseUntitled = "untitled"
+	// NoiseTemplates drops templates, named like "[Template] ..." or
+	// "... Template", and copies of them
+	NoiseTemplates = "templates"
+	// NoiseTemplatesAny drops every file with template anywhere in its name
+	NoiseTemplatesAny = "templates-any"
+	// NoiseUnedited drops files that haven't changed since they were created
+	NoiseUnedited = "unedited"
+	// NoiseTrashed drops files in the trash
+	NoiseTrashed = "trashed"
+	// NoiseNone turns every noise filter off
+	NoiseNone = "none"
+)
+
+// DefaultNoise are the filters used when a source doesn't list any
+var DefaultNoise = []string{NoiseCopies, NoiseUntitled, NoiseTemplates, NoiseUnedited, NoiseTrashed}
+
+// noiseFilters maps each noise filter to the test for files it drops
+var noiseFilters = map[string]func(f *drive.File) bool{
+	NoiseCopies: func(f *drive.File) bool {
+		return strings.HasPrefix(strings.ToLower(f.Name), "copy of ")
+	},
+	NoiseUntitled: func(f *drive.File) bool {
+		return strings.TrimSpace(f.Name) == "" || strings.HasPrefix(strings.ToLower(f.Name), "untitled")
+	},
+	NoiseTemplates: func(f *drive.File) bool {
+		name := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(f.Name)), "copy of ")
+		words := strings.FieldsFunc(name, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		return len(words) > 0 && (words[0] == "template" || words[len(words)-1] == "template")
+	},
+	NoiseTemplatesAny: func(f *drive.File) bool {
+		return strings.Contains(strings.ToLower(f.Name), "template")
+	},
+	NoiseUnedited: func(f *drive.File) bool {
+		return f.CreatedTime != "" && f.CreatedTime == f.ModifiedTime
+	},
+	NoiseTrashed: func(f *drive.File) bool {
+		return f.Trashed
+	},
+}
+
+// noise returns the names of the noise filters the source uses
+func noise(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return DefaultNoise, nil
+	}
+
+	result := []string{}
+	for _, n := range names {
+		n = strings.ToLower(n)
+		if n == NoiseNone {
+			return []string{}, nil
+		}
+		if _, ok := noiseFilters[n]; !ok {
+			return nil, fmt.Errorf("unknown noise filter %q", n)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// quiet returns the files that none of the source's noise filters drop,
+// counting the ones that are dropped by the filter that dropped them
+func (s *Source) quiet(files DriveFiles) DriveFiles {
+	result := DriveFiles{}
+
+FileLoop:
+	for _, f := range files {
+		for _, n := range s.noise {
+			if noiseFilters[n](f) {
+				s.dropped[n]++
+				continue FileLoop
+			}
+		}
+		result = append(result, f)
+	}
+
+	return result
+}
+
+// logDropped reports how many files each noise filter dropped
+func (s *Source) logDropped() {
+	if len(s.dropped) == 0 {
+		return
+	}
+
+	counts := []string{}
+	for n, c := range s.dropped {
+		counts = append(counts, fmt.Sprintf("%d %s", c, n))
+	}
+	sort.Strings(counts)
+
+	log.Infof("drive: %s dropped %s", s.name, strings.Join(counts, ", "))
+}
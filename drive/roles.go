@@ -0,0 +1,186 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tpryan/work"
+	"github.com/tpryan/work/artifact"
+	"google.golang.org/api/drive/v3"
+)
+
+// commentFields are the only comment fields requested from drive, enough to
+// tell who commented, when and what they said
+const commentFields = "nextPageToken, comments(author(emailAddress, me), content, createdTime, deleted, replies(author(emailAddress, me), content, createdTime, deleted))"
+
+// contributions returns the files owned or made by someone else that the
+// user has edited or commented on. Edited files are dated when the user last
+// modified them, and commented ones when the user last commented. A file the
+// user has both edited and commented on counts as edited.
+func (s *Source) contributions(ctx context.Context, criteria work.Criteria, editors, reviewers bool) (artifact.Artifacts, error) {
+	// Comments don't change a file's modified time, but the user must have
+	// viewed a file to comment on it
+	since := []string{"modifiedTime"}
+	if reviewers {
+		since = append(since, "viewedByMeTime")
+	}
+
+	files, err := s.files(ctx, s.query(s.collaboratorClause(), window(criteria, since...)...))
+	if err != nil {
+		return nil, err
+	}
+
+	result := artifact.Artifacts{}
+
+	for _, f := range s.quiet(files) {
+		if s.shared() {
+			// Files the user made are theirs, not contributions
+			made, err := s.created(ctx, f)
+			if err != nil {
+				return nil, err
+			}
+			if made {
+				continue
+			}
+		}
+
+		if editors {
+			if when, ok := s.modified(f); ok {
+				art, err := s.artifact(ctx, f, RoleEditor)
+				if err != nil {
+					return nil, err
+				}
+				art.ShippedDate = when
+				result = append(result, art)
+				continue
+			}
+		}
+
+		if !reviewers {
+			continue
+		}
+
+		when, ok, err := s.commented(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			art, err := s.artifact(ctx, f, RoleReviewer)
+			if err != nil {
+				return nil, err
+			}
+			art.ShippedDate = when
+			result = append(result, art)
+		}
+	}
+
+	return result, nil
+}
+
+// collaboratorClause matches files shared with the user that they don't
+// own. Like ownerClause, it has nothing to match on when shared drives are
+// searched, and created rules out the user's own files instead.
+func (s *Source) collaboratorClause() string {
+	if s.shared() {
+		return ""
+	}
+
+	owner := quote(s.owner())
+	return fmt.Sprintf("not '%s' in owners and ('%s' in writers or '%s' in readers)", owner, owner, owner)
+}
+
+// modified reports whether the user has modified a file and when they last
+// did
+func (s *Source) modified(f *drive.File) (time.Time, bool) {
+	if f.ModifiedByMeTime != "" {
+		if t, err := time.Parse(time.RFC3339, f.ModifiedByMeTime); err == nil {
+			return t, true
+		}
+	}
+
+	if s.isUser(f.LastModifyingUser) {
+		if t, err := time.Parse(time.RFC3339, f.ModifiedTime); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// commented reports whether the user has commented on, or replied to a
+// comment on, a file and when they last did
+func (s *Source) commented(ctx context.Context, f *drive.File) (time.Time, bool, error) {
+	latest := time.Time{}
+	found := false
+
+	err := s.comments(ctx, f, func(author *drive.User, created time.Time, content string) {
+		if !s.isUser(author) {
+			return
+		}
+		found = true
+		if created.After(latest) {
+			latest = created
+		}
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return latest, found, nil
+}
+
+// comments calls fn for every comment and reply on a file that hasn't been
+// deleted
+func (s *Source) comments(ctx context.Context, f *drive.File, fn func(author *drive.User, created time.Time, content string)) error {
+	note := func(author *drive.User, created, content string, deleted bool) {
+		if deleted {
+			return
+		}
+		t, err := time.Parse(time.RFC3339, created)
+		if err != nil {
+			return
+		}
+		fn(author, t, content)
+	}
+
+	var pageToken string
+	for {
+		r, err := s.svc.Comments.List(f.Id).
+			Fields(commentFields).
+			PageToken(pageToken).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return fmt.Errorf("drive comments list failed for %s: %s", f.Name, err)
+		}
+
+		for _, c := range r.Comments {
+			note(c.Author, c.CreatedTime, c.Content, c.Deleted)
+			for _, reply := range c.Replies {
+				note(reply.Author, reply.CreatedTime, reply.Content, reply.Deleted)
+			}
+		}
+
+		pageToken = r.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	return nil
+}
+
+// isUser reports whether a drive user is the one the source collects for
+func (s *Source) isUser(u *drive.User) bool {
+	if u == nil {
+		return false
+	}
+
+	if s.cfg.Owner == "" || s.cfg.Owner == "me" {
+		return u.Me
+	}
+
+	return strings.EqualFold(u.EmailAddress, s.cfg.Owner)
+}
@@ -0,0 +1,235 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tpryan/work"
+	"github.com/tpryan/work/artifact"
+	"google.golang.org/api/drive/v3"
+)
+
+// Policies for dating the files a user owns
+const (
+	// ShippedCreated dates a file by when it was created
+	ShippedCreated = "created"
+	// ShippedModified dates a file by when the user last modified it
+	ShippedModified = "modified"
+	// ShippedMarker dates a file by its first revision after a comment
+	// marking it done, such as "final" or "approved"
+	ShippedMarker = "marker"
+	// ShippedPublished dates a file by when it was first published
+	ShippedPublished = "published"
+)
+
+// DefaultMarkers are the words that mark a file done when a source doesn't
+// list any
+var DefaultMarkers = []string{"final", "approved"}
+
+// revisionFields are the only revision fields requested from drive
+const revisionFields = "nextPageToken, revisions(modifiedTime, published)"
+
+// creatorFields are the only fields requested when looking up who made a
+// file
+const creatorFields = "revisions(lastModifyingUser(emailAddress, me))"
+
+// owned returns the files the user owns, or made on shared drives, dated by
+// the configured policy.
+// Files the policy can't date, such as ones that were never published, keep
+// their created date.
+func (s *Source) owned(ctx context.Context, criteria work.Criteria) (artifact.Artifacts, error) {
+	policy := strings.ToLower(s.cfg.Shipped)
+
+	since := ""
+	switch policy {
+	case "", ShippedCreated:
+		since = "createdTime"
+	case ShippedModified, ShippedPublished:
+		since = "modifiedTime"
+	case ShippedMarker:
+		// The marker can come after the last edit, so nothing rules a file
+		// out but being created too late
+	default:
+		return nil, fmt.Errorf("unknown shipped policy %q", s.cfg.Shipped)
+	}
+
+	files, err := s.files(ctx, s.query(s.ownerClause(), window(criteria, since)...))
+	if err != nil {
+		return nil, err
+	}
+
+	arts := artifact.Artifacts{}
+
+	for _, f := range s.quiet(files) {
+		if s.shared() {
+			made, err := s.created(ctx, f)
+			if err != nil {
+				return nil, err
+			}
+			if !made {
+				continue
+			}
+		}
+
+		art, err := s.artifact(ctx, f, RoleAuthor)
+		if err != nil {
+			return nil, err
+		}
+
+		when, ok, err := s.shipped(ctx, policy, f)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			art.ShippedDate = when
+		}
+
+		arts = append(arts, art)
+	}
+
+	return arts, nil
+}
+
+// shipped returns when a file shipped under the input policy, if the policy
+// can tell
+func (s *Source) shipped(ctx context.Context, policy string, f *drive.File) (time.Time, bool, error) {
+	switch policy {
+	case ShippedModified:
+		t, ok := s.modified(f)
+		return t, ok, nil
+	case ShippedMarker:
+		return s.marked(ctx, f)
+	case ShippedPublished:
+		return s.published(ctx, f)
+	}
+	return time.Time{}, false, nil
+}
+
+// marked returns the time of the first revision made after the earliest
+// comment containing one of the configured markers, or of the comment itself
+// if the file hasn't changed since
+func (s *Source) marked(ctx context.Context, f *drive.File) (time.Time, bool, error) {
+	markers := s.cfg.Markers
+	if len(markers) == 0 {
+		markers = DefaultMarkers
+	}
+
+	marked := time.Time{}
+	err := s.comments(ctx, f, func(author *drive.User, created time.Time, content string) {
+		if !containsAny(content, markers) {
+			return
+		}
+		if marked.IsZero() || created.Before(marked) {
+			marked = created
+		}
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if marked.IsZero() {
+		return time.Time{}, false, nil
+	}
+
+	result := marked
+	err = s.revisions(ctx, f, func(r *drive.Revision, modified time.Time) {
+		if modified.After(marked) && (result.Equal(marked) || modified.Before(result)) {
+			result = modified
+		}
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return result, true, nil
+}
+
+// published returns when the first published revision of a file was made
+func (s *Source) published(ctx context.Context, f *drive.File) (time.Time, bool, error) {
+	result := time.Time{}
+	err := s.revisions(ctx, f, func(r *drive.Revision, modified time.Time) {
+		if r.Published && (result.IsZero() || modified.Before(result)) {
+			result = modified
+		}
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return result, !result.IsZero(), nil
+}
+
+// revisions calls fn for every revision of a file
+func (s *Source) revisions(ctx context.Context, f *drive.File, fn func(r *drive.Revision, modified time.Time)) error {
+	var pageToken string
+	for {
+		r, err := s.svc.Revisions.List(f.Id).
+			Fields(revisionFields).
+			PageToken(pageToken).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return fmt.Errorf("drive revisions list failed for %s: %s", f.Name, err)
+		}
+
+		for _, rev := range r.Revisions {
+			t, err := time.Parse(time.RFC3339, rev.ModifiedTime)
+			if err != nil {
+				continue
+			}
+			fn(rev, t)
+		}
+
+		pageToken = r.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+
+	return nil
+}
+
+// window returns the query clauses that rule out files that can't have
+// shipped between the criteria's Start and End. A file can't ship before it
+// was created, and since names the time fields, any one of which must be
+// after Start.
+func window(criteria work.Criteria, since ...string) []string {
+	result := []string{}
+
+	if !criteria.Start.IsZero() {
+		start := criteria.Start.UTC().Format(time.RFC3339)
+		after := []string{}
+		for _, field := range since {
+			if field != "" {
+				after = append(after, fmt.Sprintf("%s > '%s'", field, start))
+			}
+		}
+
+		switch len(after) {
+		case 0:
+		case 1:
+			result = append(result, after[0])
+		default:
+			result = append(result, "("+strings.Join(after, " or ")+")")
+		}
+	}
+
+	if !criteria.End.IsZero() {
+		result = append(result, fmt.Sprintf("createdTime < '%s'", criteria.End.UTC().Format(time.RFC3339)))
+	}
+
+	return result
+}
+
+// containsAny reports whether s contains any of the words, ignoring case
+func containsAny(s string, words []string) bool {
+	s = strings.ToLower(s)
+	for _, w := range words {
+		if w != "" && strings.Contains(s, strings.ToLower(w)) {
+			return true
+		}
+	}
+	return false
+}
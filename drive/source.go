@@ -0,0 +1,328 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tpryan/work"
+	"github.com/tpryan/work/artifact"
+	"google.golang.org/api/drive/v3"
+)
+
+// DefaultMimeList is the set of file types collected when a source doesn't
+// specify any
+var DefaultMimeList = MimeList{
+	"application/vnd.google-apps.document",
+	"application/vnd.google-apps.spreadsheet",
+	"application/vnd.google-apps.form",
+	"application/vnd.google-apps.presentation",
+	"application/vnd.google.colaboratory.corp",
+}
+
+// AllDrives in a source's drives searches My Drive and every shared drive
+// the user can see
+const AllDrives = "all"
+
+// DefaultRoles are the roles collected when a source doesn't list any
+var DefaultRoles = []string{RoleAuthor, RoleEditor, RoleReviewer}
+
+// Source collects the files a user owns, edits or comments on from Google
+// Drive
+type Source struct {
+	name     string
+	cfg      work.DriveConfig
+	svc      *drive.Service
+	rules    Rules
+	paths    map[string]string
+	drives   map[string]string
+	creators map[string]bool
+	noise    []string
+	dropped  map[string]int
+}
+
+// NewSource returns a drive source for the input configuration
+func NewSource(svc *drive.Service, cfg work.SourceConfig) (*Source, error) {
+	rules, err := NewRules(cfg.Drive.Types)
+	if err != nil {
+		return nil, fmt.Errorf("drive: source %s: %w", cfg.Name, err)
+	}
+
+	filters, err := noise(cfg.Drive.Noise)
+	if err != nil {
+		return nil, fmt.Errorf("drive: source %s: %w", cfg.Name, err)
+	}
+
+	return &Source{
+		name:     cfg.Name,
+		cfg:      cfg.Drive,
+		svc:      svc,
+		rules:    rules,
+		paths:    map[string]string{},
+		drives:   map[string]string{},
+		creators: map[string]bool{},
+		noise:    filters,
+	}, nil
+}
+
+// Name returns the name of the source
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Query returns the drive query the source runs for the files the user owns
+func (s *Source) Query() string {
+	return s.query(s.ownerClause())
+}
+
+// ownerClause matches files the user owns. Files on shared drives belong to
+// the drive rather than to whoever made them, so when shared drives are
+// searched there is nothing to match on and created picks the user's files.
+func (s *Source) ownerClause() string {
+	if s.shared() {
+		return ""
+	}
+	return fmt.Sprintf("'%s' in owners", quote(s.owner()))
+}
+
+// created reports whether the user made a file. Files in My Drive were made
+// by whoever owns them, and files on shared drives by whoever made their
+// first revision, which is only looked up for files the user has modified.
+func (s *Source) created(ctx context.Context, f *drive.File) (bool, error) {
+	if f.DriveId == "" {
+		for _, o := range f.Owners {
+			if s.isUser(o) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if _, ok := s.modified(f); !ok {
+		return false, nil
+	}
+
+	if made, ok := s.creators[f.Id]; ok {
+		return made, nil
+	}
+
+	r, err := s.svc.Revisions.List(f.Id).
+		Fields(creatorFields).
+		PageSize(1).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return false, fmt.Errorf("drive revisions list failed for %s: %s", f.Name, err)
+	}
+
+	made := len(r.Revisions) > 0 && s.isUser(r.Revisions[0].LastModifyingUser)
+	s.creators[f.Id] = made
+	return made, nil
+}
+
+// query returns a drive query for the files matched by the input clause,
+// narrowed by the configured mime types, folders and extra clauses, and then
+// by any others passed in
+func (s *Source) query(clause string, extra ...string) string {
+	mimes := DefaultMimeList
+	if len(s.cfg.Mimes) > 0 {
+		mimes = MimeList(s.cfg.Mimes)
+	}
+
+	clauses := []string{}
+	if clause != "" {
+		clauses = append(clauses, clause)
+	}
+	clauses = append(clauses, fmt.Sprintf("(%s)", mimes.String()))
+
+	if len(s.cfg.Folders) > 0 {
+		parents := []string{}
+		for _, f := range s.cfg.Folders {
+			parents = append(parents, fmt.Sprintf("'%s' in parents", quote(f)))
+		}
+		clauses = append(clauses, fmt.Sprintf("(%s)", strings.Join(parents, " or ")))
+	}
+
+	for _, c := range s.cfg.Clauses {
+		clauses = append(clauses, fmt.Sprintf("(%s)", c))
+	}
+
+	clauses = append(clauses, extra...)
+
+	return strings.Join(clauses, " and ")
+}
+
+// shared reports whether the source searches shared drives
+func (s *Source) shared() bool {
+	return len(s.cfg.Drives) > 0
+}
+
+func (s *Source) owner() string {
+	if s.cfg.Owner == "" {
+		return "me"
+	}
+	return s.cfg.Owner
+}
+
+// roles returns which of the user's roles the source collects
+func (s *Source) roles() map[string]bool {
+	roles := s.cfg.Roles
+	if len(roles) == 0 {
+		roles = DefaultRoles
+	}
+
+	result := map[string]bool{}
+	for _, r := range roles {
+		result[strings.ToLower(r)] = true
+	}
+	return result
+}
+
+// Windowed reports that Collect only asks for files from the criteria's
+// window
+func (s *Source) Windowed() bool {
+	return true
+}
+
+// Collect returns the files in drive that the user owns, has edited or has
+// commented on, as configured. Only files that could have shipped between
+// the criteria's Start and End are asked for, and files the noise filters
+// drop are left out.
+func (s *Source) Collect(ctx context.Context, criteria work.Criteria) (artifact.Artifacts, error) {
+	roles := s.roles()
+	result := artifact.Artifacts{}
+
+	s.dropped = map[string]int{}
+	defer s.logDropped()
+
+	if roles[strings.ToLower(RoleAuthor)] {
+		arts, err := s.owned(ctx, criteria)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving data from drive: %w", err)
+		}
+		result = append(result, arts...)
+	}
+
+	if roles[RoleEditor] || roles[RoleReviewer] {
+		arts, err := s.contributions(ctx, criteria, roles[RoleEditor], roles[RoleReviewer])
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving contributions from drive: %w", err)
+		}
+		result = append(result, arts...)
+	}
+
+	return result, nil
+}
+
+// files returns every file matching the query in My Drive and each
+// configured shared drive, or in every drive if they include AllDrives
+func (s *Source) files(ctx context.Context, q string) (DriveFiles, error) {
+	if !s.shared() {
+		return list(ctx, s.listCall(q), s.pageSize())
+	}
+
+	for _, id := range s.cfg.Drives {
+		if strings.EqualFold(id, AllDrives) {
+			call := s.listCall(q).
+				Corpora("allDrives").
+				IncludeItemsFromAllDrives(true).
+				SupportsAllDrives(true)
+			return list(ctx, call, s.pageSize())
+		}
+	}
+
+	result, err := list(ctx, s.listCall(q).Corpora("user"), s.pageSize())
+	if err != nil {
+		return nil, fmt.Errorf("my drive: %w", err)
+	}
+
+	for _, id := range s.cfg.Drives {
+		call := s.listCall(q).
+			Corpora("drive").
+			DriveId(id).
+			IncludeItemsFromAllDrives(true).
+			SupportsAllDrives(true)
+
+		files, err := list(ctx, call, s.pageSize())
+		if err != nil {
+			return nil, fmt.Errorf("shared drive %s: %w", id, err)
+		}
+		result = append(result, files...)
+	}
+
+	return result, nil
+}
+
+// listCall returns a files list call for the query that includes the labels
+// the type rules look for
+func (s *Source) listCall(q string) *drive.FilesListCall {
+	call := s.svc.Files.List().Q(q)
+	if labels := s.rules.labels(); len(labels) > 0 {
+		call = call.IncludeLabels(strings.Join(labels, ","))
+	}
+	return call
+}
+
+// artifact returns the artifact for a file with the input role, typed by the
+// source's rules and with the path of its folder and the name of its shared
+// drive so it can be classified by where it lives
+func (s *Source) artifact(ctx context.Context, f *drive.File, role string) (artifact.Artifact, error) {
+	art := DriveFiles{f}.ArtifactsAs(role)[0]
+
+	path, err := s.path(ctx, f)
+	if err != nil {
+		return art, err
+	}
+
+	name, err := s.driveName(ctx, f.DriveId)
+	if err != nil {
+		return art, err
+	}
+
+	attrs := map[string]string{}
+	if path != "" {
+		attrs[artifact.FolderAttribute] = path
+	}
+	if name != "" {
+		attrs[artifact.DriveAttribute] = name
+	}
+	if len(attrs) > 0 {
+		art.Attributes = attrs
+	}
+
+	art.Type = s.rules.Type(f, path)
+	return art, nil
+}
+
+// driveName returns the name of a shared drive, looking each one up once per
+// source. Files in My Drive have no drive ID and no name.
+func (s *Source) driveName(ctx context.Context, id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+
+	if name, ok := s.drives[id]; ok {
+		return name, nil
+	}
+
+	d, err := s.svc.Drives.Get(id).Fields("name").Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("drive lookup failed for %s: %s", id, err)
+	}
+
+	s.drives[id] = d.Name
+	return d.Name, nil
+}
+
+// pageSize returns how many files to ask drive for at a time
+func (s *Source) pageSize() int64 {
+	if s.cfg.PageSize > 0 {
+		return int64(s.cfg.PageSize)
+	}
+	return DefaultPageSize
+}
+
+// quote escapes a value for use inside single quotes in a drive query
+func quote(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
@@ -0,0 +1,159 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tpryan/work"
+	"google.golang.org/api/drive/v3"
+)
+
+// Rule sets the Type of the drive files that match all of its conditions.
+// Empty conditions match everything.
+type Rule struct {
+	Type   string
+	title  *regexp.Regexp
+	mime   string
+	folder *regexp.Regexp
+	label  string
+}
+
+// Rules is an ordered collection of type rules. The first rule to match a
+// file sets its Type.
+type Rules []Rule
+
+// DefaultRules are the rules every source falls back to: design docs by
+// name, then the common Google file types, then a catch-all
+var DefaultRules = Rules{
+	{Type: "Design Doc", title: regexp.MustCompile(`(?i)prd|tdd`)},
+	{Type: "Sheet", mime: "application/vnd.google-apps.spreadsheet"},
+	{Type: "Doc", mime: "application/vnd.google-apps.document"},
+	{Type: "Slides", mime: "application/vnd.google-apps.presentation"},
+	{Type: "Colab", mime: "application/vnd.google.colaboratory.corp"},
+	{Type: "Form", mime: "application/vnd.google-apps.form"},
+	{Type: "File"},
+}
+
+// NewRules compiles configured type rules, followed by the DefaultRules for
+// any file none of them match. Title and folder patterns are regular
+// expressions matched without regard to case.
+func NewRules(cfg []work.DriveTypeRule) (Rules, error) {
+	result := Rules{}
+
+	for i, c := range cfg {
+		if c.Type == "" {
+			return nil, fmt.Errorf("type rule %d has no type", i+1)
+		}
+
+		r := Rule{Type: c.Type, mime: c.Mime, label: c.Label}
+
+		var err error
+		if r.title, err = compile(c.Title); err != nil {
+			return nil, fmt.Errorf("type rule %d has a bad title pattern: %w", i+1, err)
+		}
+		if r.folder, err = compile(c.Folder); err != nil {
+			return nil, fmt.Errorf("type rule %d has a bad folder pattern: %w", i+1, err)
+		}
+
+		result = append(result, r)
+	}
+
+	return append(result, DefaultRules...), nil
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// Match reports whether a file, in the folder at path, meets every
+// condition of the rule
+func (r Rule) Match(f *drive.File, path string) bool {
+	if r.title != nil && !r.title.MatchString(f.Name) {
+		return false
+	}
+	if r.mime != "" && r.mime != f.MimeType {
+		return false
+	}
+	if r.folder != nil && !r.folder.MatchString(path) {
+		return false
+	}
+	if r.label != "" && !hasLabel(f, r.label) {
+		return false
+	}
+	return true
+}
+
+// Type returns the Type of the first rule a file matches, or "File" if
+// none do
+func (r Rules) Type(f *drive.File, path string) string {
+	for _, rule := range r {
+		if rule.Match(f, path) {
+			return rule.Type
+		}
+	}
+	return "File"
+}
+
+// labels returns the IDs of the labels the rules look for
+func (r Rules) labels() []string {
+	result := []string{}
+	for _, rule := range r {
+		if rule.label != "" {
+			result = append(result, rule.label)
+		}
+	}
+	return result
+}
+
+func hasLabel(f *drive.File, id string) bool {
+	if f.LabelInfo == nil {
+		return false
+	}
+	for _, l := range f.LabelInfo.Labels {
+		if l.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// path returns the folder path of a file, such as "My Drive/Team/Designs",
+// looking up each parent folder once per source
+func (s *Source) path(ctx context.Context, f *drive.File) (string, error) {
+	if len(f.Parents) == 0 {
+		return "", nil
+	}
+	return s.folderPath(ctx, f.Parents[0])
+}
+
+func (s *Source) folderPath(ctx context.Context, id string) (string, error) {
+	if p, ok := s.paths[id]; ok {
+		return p, nil
+	}
+
+	folder, err := s.svc.Files.Get(id).
+		Fields("name, parents").
+		SupportsAllDrives(true).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return "", fmt.Errorf("drive folder lookup failed for %s: %s", id, err)
+	}
+
+	p := folder.Name
+	if len(folder.Parents) > 0 {
+		parent, err := s.folderPath(ctx, folder.Parents[0])
+		if err != nil {
+			return "", err
+		}
+		p = strings.Join([]string{parent, folder.Name}, "/")
+	}
+
+	s.paths[id] = p
+	return p, nil
+}
@@ -12,9 +12,10 @@ import (
 	"github.com/tpryan/work"
 	"github.com/tpryan/work/artifact"
 	"github.com/tpryan/work/drive"
+	"github.com/tpryan/work/file"
 	"github.com/tpryan/work/github"
 	"github.com/tpryan/work/gsheet"
-	gdrive "google.golang.org/api/drive/v2"
+	gdrive "google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 )
@@ -29,6 +30,7 @@ var scopes = []string{
 
 func main() {
 	var userFlag = flag.String("user", "", "user who should be run on")
+	var dryRunFlag = flag.Bool("dry-run", false, "print the changes that would be made without writing anything")
 	flag.Parse()
 
 	user := *userFlag
@@ -70,95 +72,191 @@ func main() {
 		log.Fatalf("unable to retrieve Sheets client: %v", err)
 	}
 
-	gsheet := gsheet.New(*sheetsSVC, config.SpreadSheetID)
+	gsheet := gsheet.New(gsheet.NewService(sheetsSVC), config.SpreadSheetID)
 
-	log.Infof("Processing Github")
-	if err := processGithub(config.GithubUser, gsheet); err != nil {
-		log.Error("unable to retrieve latest github info: %s", err)
+	registry := newRegistry(&gsheet, driveSVC)
+	sinks := newSinkRegistry(&gsheet)
+
+	log.Infof("Collecting sources")
+	all, err := collect(ctx, registry, config.SourceList(user), config.Destinations.Window(), gsheet, *dryRunFlag)
+	if err != nil {
+		log.Fatalf("unable to collect artifacts: %s", err)
 	}
 
-	if config.QueryDrive {
-		log.Infof("Processing Drive")
-		if err := processDrive(driveSVC, gsheet, user); err != nil {
-			log.Errorf("unable to retrieve latest drive info: %s", err)
-		}
+	overrides, err := loadOverrides(ctx, registry, config.Overrides)
+	if err != nil {
+		log.Fatalf("unable to read overrides: %s", err)
 	}
 
 	log.Infof("Writing report")
-	if err := writeReport(gsheet, config.Sources, config.Destinations, config.Classifiers); err != nil {
-		log.Error(fmt.Sprintf("unable to write report to sheets: %s", err))
+	if err := writeReport(ctx, sinks, all, overrides, config.Destinations, config.Classifiers, gsheet, *dryRunFlag); err != nil {
+		log.Error(fmt.Sprintf("unable to write report: %s", err))
 	}
 	log.Infof("...Finished")
 
 }
 
-func processDrive(svc *gdrive.Service, gsheet gsheet.GSheet, user string) error {
+func newRegistry(g *gsheet.GSheet, driveSVC *gdrive.Service) work.SourceRegistry {
+	registry := work.SourceRegistry{}
+
+	registry.Register(work.SourceSheet, func(cfg work.SourceConfig) (work.Source, error) {
+		return gsheet.NewSource(g, cfg), nil
+	})
+	registry.Register(work.SourceGithub, func(cfg work.SourceConfig) (work.Source, error) {
+		return github.NewSource(cfg)
+	})
+	registry.Register(work.SourceDrive, func(cfg work.SourceConfig) (work.Source, error) {
+		return drive.NewSource(driveSVC, cfg)
+	})
+	registry.Register(work.SourceFile, func(cfg work.SourceConfig) (work.Source, error) {
+		return file.NewSource(cfg)
+	})
+
+	return registry
+}
 
-	mlist := drive.MimeList{
-		"application/vnd.google-apps.document",
-		"application/vnd.google-apps.spreadsheet",
-		"application/vnd.google-apps.form",
-		"application/vnd.google-apps.presentation",
-		"application/vnd.google.colaboratory.corp",
-	}
+func newSinkRegistry(g *gsheet.GSheet) work.SinkRegistry {
+	sinks := work.SinkRegistry{}
 
-	query := fmt.Sprintf("'%s@google.com' in owners and (%s)", user, mlist.String())
+	sinks.Register(work.SinkSheet, func(dest work.Destination) (work.Sink, error) {
+		switch dest.Mode {
+		case "", work.ModeReplace:
+			return g, nil
+		case work.ModeIncremental:
+			return gsheet.NewIncremental(g), nil
+		}
+		return nil, fmt.Errorf("unknown mode %q for sheet %s", dest.Mode, dest.Sheet)
+	})
+	sinks.Register(work.SinkFile, func(dest work.Destination) (work.Sink, error) {
+		return file.NewSink(dest)
+	})
 
-	arts, err := drive.Search(query, svc)
-	if err != nil {
-		return fmt.Errorf("error retrieving data from drive: %w", err)
-	}
+	return sinks
+}
+
+// collect gathers artifacts from every configured source, limited to the
+// window the destinations cover. Sources with a snapshot sheet record their
+// results there, unless this is a dry run or the source only collected the
+// window, and fall back to the last snapshot if they fail. Sources that only keep a snapshot
+// are left out of what is returned.
+func collect(ctx context.Context, registry work.SourceRegistry, sources work.SourceConfigs, window work.Criteria, gsheet gsheet.GSheet, dryRun bool) (artifact.Artifacts, error) {
+	all := artifact.Artifacts{}
+
+	for _, cfg := range sources {
+		src, err := registry.New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("unable to set up source %s: %w", cfg.Name, err)
+		}
+
+		log.Infof("Processing %s", src.Name())
+		arts, err := src.Collect(ctx, window)
+		if err != nil {
+			if cfg.Snapshot == "" {
+				return nil, fmt.Errorf("unable to collect from %s: %w", src.Name(), err)
+			}
+
+			if cfg.SnapshotOnly {
+				log.Errorf("unable to retrieve latest info from %s, leaving snapshot %s as is: %s", src.Name(), cfg.Snapshot, err)
+				continue
+			}
 
-	arts.Sort()
+			log.Errorf("unable to retrieve latest info from %s, using snapshot: %s", src.Name(), err)
+			arts, err = gsheet.Artifacts(cfg.Snapshot)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read snapshot %s: %w", cfg.Snapshot, err)
+			}
+			all = append(all, arts...)
+			continue
+		}
+
+		// A windowed collection only has part of the source's history, and
+		// writing it would cut the snapshot down to that window
+		bounded := windowed(src) && (!window.Start.IsZero() || !window.End.IsZero())
+		if cfg.Snapshot != "" && bounded {
+			log.Infof("Leaving snapshot %s as is, only a window of %s was collected", cfg.Snapshot, src.Name())
+		}
+
+		if cfg.Snapshot != "" && !bounded && dryRun {
+			log.Infof("Would write %d rows to snapshot %s", len(arts), cfg.Snapshot)
+		}
 
-	if err := gsheet.ToSheet("Source - DriveFiles", arts); err != nil {
-		return fmt.Errorf("error writing to sheet: %w", err)
+		if cfg.Snapshot != "" && !bounded && !dryRun {
+			arts.Sort()
+			if err := gsheet.ToSheet(cfg.Snapshot, arts); err != nil {
+				log.Errorf("error writing to sheet %s: %s", cfg.Snapshot, err)
+			}
+		}
+
+		if cfg.SnapshotOnly {
+			continue
+		}
+		all = append(all, arts...)
 	}
 
-	return nil
+	return all, nil
 }
 
-func processGithub(username string, gsheet gsheet.GSheet) error {
-	q := fmt.Sprintf("author:%s is:pr state:closed", username)
+// windowed reports whether a source narrows what it collects to the window
+func windowed(src work.Source) bool {
+	w, ok := src.(work.Windowed)
+	return ok && w.Windowed()
+}
 
-	gartifacts, err := github.Search(q)
-	if err != nil {
-		return fmt.Errorf("could not get issues: %w", err)
+// loadOverrides reads the manual classifications kept in the overrides
+// source, if one is configured
+func loadOverrides(ctx context.Context, registry work.SourceRegistry, cfg work.SourceConfig) (artifact.Artifacts, error) {
+	if cfg.Type == "" {
+		return artifact.Artifacts{}, nil
 	}
 
-	if err := gsheet.ToSheet("Source - Github", gartifacts); err != nil {
-		return fmt.Errorf("error writing to sheet: %w", err)
+	src, err := registry.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to set up overrides %s: %w", cfg.Name, err)
 	}
 
-	return nil
+	log.Infof("Reading overrides from %s", src.Name())
+	return src.Collect(ctx, work.Criteria{})
 }
 
-func writeReport(gsheet gsheet.GSheet, sources []string, destinations work.Destinations, list artifact.Classifiers) error {
-	all := artifact.Artifacts{}
-
-	log.Infof("Getting Sources")
-	for _, source := range sources {
-		arts, err := gsheet.Artifacts(source)
-
+// writeReport classifies and filters the artifacts for each destination and
+// writes them out. Overrides, and for destinations that keep edits any
+// changes made there by hand, are applied after classification so manual
+// changes to Type, Project and Subproject stick. What classification gave
+// is recorded for destinations that keep edits, so the next run can tell
+// hand edits apart from values computed under older rules.
+func writeReport(ctx context.Context, sinks work.SinkRegistry, all artifact.Artifacts, overrides artifact.Artifacts, destinations work.Destinations, list artifact.Classifiers, gsheet gsheet.GSheet, dryRun bool) error {
+	targets := []work.Sink{}
+	for _, dest := range destinations {
+		sink, err := sinks.New(dest)
 		if err != nil {
-			return fmt.Errorf("unable to retrieve sheets client: %w", err)
+			return fmt.Errorf("unable to set up destination %s: %w", dest.Sheet, err)
 		}
-		all = append(all, arts...)
-
+		targets = append(targets, sink)
 	}
 
 	var wg sync.WaitGroup
 	wg.Add(len(destinations))
 
-	log.Infof("Writing to Sheet")
-	for _, dest := range destinations {
+	log.Infof("Writing to destinations")
+	for i, dest := range destinations {
 
-		go func(all artifact.Artifacts, dest work.Destination) {
+		go func(all artifact.Artifacts, dest work.Destination, sink work.Sink) {
 			artifacts := all.Copy()
 
 			artifacts.Massage(
 				artifact.Between(dest.Criteria.Start, dest.Criteria.End),
 				artifact.Classify(list),
+			)
+			classified := artifacts.Copy()
+
+			edits, err := currentEdits(ctx, sink, dest, gsheet, classified)
+			if err != nil {
+				log.Errorf("unable to read edits from %s: %s", dest.Sheet, err)
+			}
+
+			artifacts.Massage(
+				artifact.Override(edits),
+				artifact.Override(overrides),
 				artifact.ProjectFilter(dest.Criteria.Project),
 				artifact.Unique(),
 			)
@@ -170,9 +268,19 @@ func writeReport(gsheet gsheet.GSheet, sources []string, destinations work.Desti
 				artifacts.Sort()
 			}
 
-			log.Infof("Writing to %s", dest.Sheet)
-			if err := gsheet.ToSheet(dest.Sheet, artifacts); err != nil {
-				log.Errorf("error writing to sheet %s: %s", dest.Sheet, err)
+			if dryRun {
+				if err := printPlan(ctx, sink, dest.Sheet, artifacts); err != nil {
+					log.Errorf("error planning %s: %s", dest.Sheet, err)
+				}
+			} else {
+				log.Infof("Writing to %s", dest.Sheet)
+				if err := sink.Write(ctx, dest.Sheet, artifacts); err != nil {
+					log.Errorf("error writing to %s: %s", dest.Sheet, err)
+				} else if dest.KeepEdits {
+					if err := gsheet.ToSheet(dest.ClassifiedSheet(), classified); err != nil {
+						log.Errorf("error writing to sheet %s: %s", dest.ClassifiedSheet(), err)
+					}
+				}
 			}
 
 			if dest.Summary {
@@ -180,10 +288,55 @@ func writeReport(gsheet gsheet.GSheet, sources []string, destinations work.Desti
 			}
 
 			wg.Done()
-		}(all, dest)
+		}(all, dest, targets[i])
 
 	}
 
 	wg.Wait()
 	return nil
 }
+
+// currentEdits returns the changes made by hand to a destination, for
+// destinations that keep them. Values are compared with what classification
+// gave them when the destination was last written, or for artifacts with no
+// record of that, with what it gives them now.
+func currentEdits(ctx context.Context, sink work.Sink, dest work.Destination, gsheet gsheet.GSheet, classified artifact.Artifacts) (artifact.Artifacts, error) {
+	if !dest.KeepEdits {
+		return artifact.Artifacts{}, nil
+	}
+
+	r, ok := sink.(work.Reader)
+	if !ok {
+		return artifact.Artifacts{}, fmt.Errorf("destination can't be read back")
+	}
+
+	current, err := r.Read(ctx, dest.Sheet)
+	if err != nil {
+		return artifact.Artifacts{}, err
+	}
+
+	record, err := gsheet.Read(ctx, dest.ClassifiedSheet())
+	if err != nil {
+		return artifact.Artifacts{}, fmt.Errorf("unable to read %s: %w", dest.ClassifiedSheet(), err)
+	}
+
+	return artifact.Edits(current, append(record, classified...)), nil
+}
+
+// printPlan shows what writing to a destination would change, for sinks that
+// can tell, or just how much would be written for those that can't
+func printPlan(ctx context.Context, sink work.Sink, name string, artifacts artifact.Artifacts) error {
+	dr, ok := sink.(work.DryRunner)
+	if !ok {
+		fmt.Printf("%s: %d rows would be written\n", name, len(artifacts))
+		return nil
+	}
+
+	plan, err := dr.DryRun(ctx, name, artifacts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(plan)
+	return nil
+}
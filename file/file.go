@@ -0,0 +1,149 @@
+// Package file reads and writes artifacts to local CSV, TSV, JSON, JSON Lines
+// and YAML files so that the whole pipeline can run without a spreadsheet
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tpryan/work"
+	"github.com/tpryan/work/artifact"
+)
+
+var errUnsupportedFormat = fmt.Errorf("file: unsupported file format")
+
+// Encode writes artifacts to w in the format implied by the extension of path
+func Encode(w io.Writer, path string, a artifact.Artifacts) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return a.WriteCSV(w, ',')
+	case ".tsv":
+		return a.WriteCSV(w, '\t')
+	case ".json":
+		return a.WriteJSON(w)
+	case ".jsonl", ".ndjson":
+		return a.WriteJSONLines(w)
+	case ".yaml", ".yml":
+		return a.WriteYAML(w)
+	}
+
+	return fmt.Errorf("%w: %s", errUnsupportedFormat, path)
+}
+
+// Decode reads artifacts from r in the format implied by the extension of
+// path
+func Decode(r io.Reader, path string) (artifact.Artifacts, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return artifact.ReadCSV(r, ',')
+	case ".tsv":
+		return artifact.ReadCSV(r, '\t')
+	case ".json":
+		return artifact.ReadJSON(r)
+	case ".jsonl", ".ndjson":
+		return artifact.ReadJSONLines(r)
+	case ".yaml", ".yml":
+		return artifact.ReadYAML(r)
+	}
+
+	return nil, fmt.Errorf("%w: %s", errUnsupportedFormat, path)
+}
+
+// Read returns the artifacts stored in the file at path
+func Read(path string) (artifact.Artifacts, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("file: could not open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	arts, err := Decode(f, path)
+	if err != nil {
+		return nil, fmt.Errorf("file: could not read %s: %w", path, err)
+	}
+
+	return arts, nil
+}
+
+// Write replaces the contents of the file at path with the artifacts
+func Write(path string, a artifact.Artifacts) error {
+	var buf bytes.Buffer
+
+	if err := Encode(&buf, path, a); err != nil {
+		return fmt.Errorf("file: could not encode %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("file: could not write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Source reads artifacts from a local file
+type Source struct {
+	name string
+	path string
+}
+
+// NewSource returns a source for the file in the input configuration
+func NewSource(cfg work.SourceConfig) (*Source, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("file: source %s has no path configured", cfg.Name)
+	}
+
+	return &Source{name: cfg.Name, path: cfg.Path}, nil
+}
+
+// Name returns the name of the source
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Collect returns the artifacts stored in the file
+func (s *Source) Collect(ctx context.Context, criteria work.Criteria) (artifact.Artifacts, error) {
+	return Read(s.path)
+}
+
+// Sink writes reports to a local file
+type Sink struct {
+	path string
+}
+
+// NewSink returns a sink for the file in the input destination
+func NewSink(dest work.Destination) (*Sink, error) {
+	if dest.Path == "" {
+		return nil, fmt.Errorf("file: destination %s has no path configured", dest.Sheet)
+	}
+
+	return &Sink{path: dest.Path}, nil
+}
+
+// Write replaces the contents of the file with the artifacts
+func (s *Sink) Write(ctx context.Context, name string, a artifact.Artifacts) error {
+	return Write(s.path, a)
+}
+
+// Read returns the artifacts currently in the file. A file that doesn't
+// exist yet has none.
+func (s *Sink) Read(ctx context.Context, name string) (artifact.Artifacts, error) {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return artifact.Artifacts{}, nil
+	}
+	return Read(s.path)
+}
+
+// DryRun describes what Write would do to the file
+func (s *Sink) DryRun(ctx context.Context, name string, a artifact.Artifacts) (string, error) {
+	state := "existing file"
+	if _, err := os.Stat(s.path); err != nil {
+		state = "new file"
+	}
+
+	return fmt.Sprintf("%s (%s): %d rows would be written\n", s.path, state, len(a)), nil
+}
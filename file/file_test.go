@@ -0,0 +1,136 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tpryan/work"
+	"github.com/tpryan/work/artifact"
+)
+
+func TestRoundTrip(t *testing.T) {
+	in := artifact.Artifacts{
+		artifact.Artifact{
+			Title:       "Title, with comma",
+			Type:        "Type",
+			Link:        "http://example.com",
+			Project:     "Proj",
+			Subproject:  "Sub",
+			Role:        "Role",
+			ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := map[string]struct {
+		file   string
+		errStr string
+	}{
+		"csv": {
+			file: "report.csv",
+		},
+		"tsv": {
+			file: "report.TSV",
+		},
+		"json": {
+			file: "report.json",
+		},
+		"jsonl": {
+			file: "report.jsonl",
+		},
+		"yaml": {
+			file: "report.yml",
+		},
+		"unsupported": {
+			file:   "report.xls",
+			errStr: "unsupported file format",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), tc.file)
+
+			sink, err := NewSink(work.Destination{Sheet: name, Sink: work.SinkFile, Path: path})
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			err = sink.Write(ctx, name, in)
+			if tc.errStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+					t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			src, err := NewSource(work.SourceConfig{Name: name, Type: work.SourceFile, Path: path})
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			got, err := src.Collect(ctx, work.Criteria{})
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.Equal(t, in, got)
+		})
+	}
+}
+
+func TestSourceCollect(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		noFile  bool
+		errStr  string
+	}{
+		"missing": {
+			noFile: true,
+			errStr: "could not open",
+		},
+		"garbage": {
+			content: "not,a,report\n",
+			errStr:  "could not read",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "source.csv")
+			if !tc.noFile {
+				if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+					t.Fatalf("could not set up test file: %s", err)
+				}
+			}
+
+			src, err := NewSource(work.SourceConfig{Name: name, Path: path})
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			_, err = src.Collect(context.Background(), work.Criteria{})
+			if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+				t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+			}
+		})
+	}
+}
+
+func TestNewMissingPath(t *testing.T) {
+	if _, err := NewSource(work.SourceConfig{Name: "src"}); err == nil {
+		t.Fatalf("expected an error for a source without a path")
+	}
+
+	if _, err := NewSink(work.Destination{Sheet: "dest"}); err == nil {
+		t.Fatalf("expected an error for a destination without a path")
+	}
+}
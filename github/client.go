@@ -0,0 +1,139 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/go-github/github"
+	"github.com/tpryan/work"
+	"golang.org/x/oauth2"
+)
+
+// TokenEnv is the environment variable a token is read from when the config
+// doesn't say where to find one
+const TokenEnv = "GITHUB_TOKEN"
+
+// defaultAbuseWait is how long to back off from a secondary rate limit that
+// doesn't say how long to wait
+const defaultAbuseWait = time.Minute
+
+// maxRetries is how many times a single page will be retried after hitting
+// a rate limit before giving up
+const maxRetries = 5
+
+// sleep waits for d or until the context is done. It is a variable so tests
+// don't have to wait.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Token returns the github token for a source. It is taken from the config
+// itself, then a file, then the named environment variable, falling back to
+// GITHUB_TOKEN. An empty token means searches are made anonymously.
+func Token(cfg work.GithubConfig) (string, error) {
+	if cfg.Token != "" {
+		return cfg.Token, nil
+	}
+
+	if cfg.TokenFile != "" {
+		dat, err := os.ReadFile(cfg.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("github: couldn't read token file: %s", err)
+		}
+		return strings.TrimSpace(string(dat)), nil
+	}
+
+	env := cfg.TokenEnv
+	if env == "" {
+		env = TokenEnv
+	}
+
+	return os.Getenv(env), nil
+}
+
+// NewClient returns a github client that authenticates with the input
+// token, or an anonymous one if the token is empty
+func NewClient(ctx context.Context, token string) *github.Client {
+	return github.NewClient(httpClient(ctx, token))
+}
+
+// NewEnterpriseClient returns a client for a GitHub Enterprise Server. The
+// upload URL defaults to the base URL. An empty base URL gives a client for
+// github.com.
+func NewEnterpriseClient(ctx context.Context, token, baseURL, uploadURL string) (*github.Client, error) {
+	if baseURL == "" {
+		return NewClient(ctx, token), nil
+	}
+
+	if uploadURL == "" {
+		uploadURL = baseURL
+	}
+
+	client, err := github.NewEnterpriseClient(baseURL, uploadURL, httpClient(ctx, token))
+	if err != nil {
+		return nil, fmt.Errorf("github: invalid enterprise url: %s", err)
+	}
+
+	return client, nil
+}
+
+func httpClient(ctx context.Context, token string) *http.Client {
+	if token == "" {
+		return nil
+	}
+
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	return oauth2.NewClient(ctx, ts)
+}
+
+// retry works out how long to wait before trying a request again after it
+// failed. It returns false for errors that aren't rate limits.
+func retry(err error) (time.Duration, bool) {
+	switch e := err.(type) {
+	case *github.RateLimitError:
+		wait := time.Until(e.Rate.Reset.Time)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait + time.Second, true
+	case *github.AbuseRateLimitError:
+		if e.RetryAfter != nil {
+			return *e.RetryAfter, true
+		}
+		return defaultAbuseWait, true
+	}
+	return 0, false
+}
+
+// withRetry runs a search call, waiting out rate limits rather than failing
+func withRetry(ctx context.Context, call func() (*github.Response, error)) (*github.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := call()
+		if err == nil {
+			return resp, nil
+		}
+
+		wait, ok := retry(err)
+		if !ok || attempt >= maxRetries {
+			return resp, err
+		}
+
+		log.Warnf("github: rate limited, waiting %s before retrying", wait.Round(time.Second))
+		if err := sleep(ctx, wait); err != nil {
+			return resp, err
+		}
+	}
+}
@@ -2,30 +2,79 @@ package github
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/github"
 	"github.com/tpryan/work/artifact"
 )
 
+// Kinds of github search
+const (
+	KindIssues  = "issues"
+	KindCommits = "commits"
+)
+
+// Types and roles given to github artifacts
+const (
+	TypePullRequest         = "Pull Request"
+	TypeUnmergedPullRequest = "Unmerged Pull Request"
+	TypeIssue               = "Issue"
+	TypeCommit              = "Commit"
+
+	RoleAuthor   = "author"
+	RoleReviewer = "reviewer"
+	RoleAssignee = "assignee"
+)
+
 // Issues is a collection of github issues
 type Issues []*github.Issue
 
 // Artifacts returns a collection of artifacts from a collection of github issues
 func (g Issues) Artifacts() artifact.Artifacts {
+	return g.ArtifactsAs(TypePullRequest, RoleAuthor)
+}
+
+// ArtifactsAs returns a collection of artifacts from a collection of github
+// issues with the given Type and Role. If typ is empty, each artifact is
+// typed as a pull request or an issue according to what it is. Open issues
+// are dated when they were created. Links are the issue's web page, which is
+// worked out from the API URL for results that don't include it.
+func (g Issues) ArtifactsAs(typ, role string) artifact.Artifacts {
 
 	linkreplacer := strings.NewReplacer("api.", "", "/repos/", "/")
 	gartifacts := artifact.Artifacts{}
 
 	for _, v := range g {
 
+		t := typ
+		if t == "" {
+			t = TypeIssue
+			if v.IsPullRequest() {
+				t = TypePullRequest
+			}
+		}
+
+		link := v.GetHTMLURL()
+		if link == "" {
+			link = linkreplacer.Replace(v.GetURL())
+		}
+
+		shipped := v.GetClosedAt()
+		if shipped.IsZero() {
+			shipped = v.GetCreatedAt()
+		}
+
 		art := artifact.Artifact{
-			Type:        "Pull Request",
-			Role:        "author",
+			Type:        t,
+			Role:        role,
 			Title:       v.GetTitle(),
-			ShippedDate: v.GetClosedAt(),
-			Link:        linkreplacer.Replace(v.GetURL()),
+			ShippedDate: shipped,
+			Link:        link,
 		}
 
 		gartifacts = append(gartifacts, art)
@@ -34,36 +83,130 @@ func (g Issues) Artifacts() artifact.Artifacts {
 	return gartifacts
 }
 
-// Search returns results from github as artifacts
-func Search(q string) (artifact.Artifacts, error) {
+// Commits is a collection of github commit search results
+type Commits []*github.CommitResult
+
+// ArtifactsAs returns a collection of artifacts from a collection of github
+// commits with the given Type and Role. The title is the first line of the
+// commit message.
+func (c Commits) ArtifactsAs(typ, role string) artifact.Artifacts {
+	if typ == "" {
+		typ = TypeCommit
+	}
+
+	gartifacts := artifact.Artifacts{}
 
-	results := []*github.Issue{}
-	page := 1
-	client := github.NewClient(nil)
+	for _, v := range c {
+		commit := v.GetCommit()
+		title, _, _ := strings.Cut(commit.GetMessage(), "\n")
 
-	opts := &github.SearchOptions{
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
+		art := artifact.Artifact{
+			Type:        typ,
+			Role:        role,
+			Title:       strings.TrimSpace(title),
+			ShippedDate: commit.GetCommitter().GetDate(),
+			Link:        v.GetHTMLURL(),
+		}
+
+		gartifacts = append(gartifacts, art)
 	}
 
-	for page > 0 {
-		opts.Page = page
+	return gartifacts
+}
 
-		result, response, err := client.Search.Issues(context.Background(), q, opts)
+// Search returns results from github as artifacts, signed in with the token
+// in GITHUB_TOKEN if it is set
+func Search(q string) (artifact.Artifacts, error) {
+	ctx := context.Background()
+
+	results, err := searchIssues(ctx, NewClient(ctx, os.Getenv(TokenEnv)), q)
+	if err != nil {
+		return nil, err
+	}
+
+	return results.Artifacts(), nil
+}
+
+// searchIssues returns every issue matching the query, splitting it into
+// date windows if it matches more than search will return. Merged pull
+// requests are closed when they merge, but are given the time they merged as
+// their closed time in case the two differ.
+func searchIssues(ctx context.Context, client *github.Client, q string) (Issues, error) {
+	fetch := func(ctx context.Context, q string, page int) ([]*github.Issue, int, int, error) {
+		u := fmt.Sprintf("search/issues?q=%s&page=%d&per_page=100", url.QueryEscape(q), page)
+		req, err := client.NewRequest("GET", u, nil)
 		if err != nil {
-			return nil, fmt.Errorf("github: could not search events: %s", err)
+			return nil, 0, 0, fmt.Errorf("github: could not search events: %s", err)
 		}
 
-		for _, v := range (*result).Issues {
-			// redirect here because there were issues with pass by value
-			tmp := v
-			results = append(results, &tmp)
+		result := issuesResult{}
+		response, err := withRetry(ctx, func() (*github.Response, error) {
+			return client.Do(ctx, req, &result)
+		})
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("github: could not search events: %s", err)
+		}
+
+		results := []*github.Issue{}
+		for _, item := range result.Items {
+			issue := &github.Issue{}
+			if err := json.Unmarshal(item, issue); err != nil {
+				return nil, 0, 0, fmt.Errorf("github: could not read search result: %s", err)
+			}
+
+			merged := mergedResult{}
+			if err := json.Unmarshal(item, &merged); err == nil && merged.PullRequest != nil && merged.PullRequest.MergedAt != nil {
+				issue.ClosedAt = merged.PullRequest.MergedAt
+			}
+
+			results = append(results, issue)
 		}
 
-		page = response.NextPage
+		return results, result.Total, response.NextPage, nil
 	}
 
-	return Issues(results).Artifacts(), nil
+	return paginate(ctx, q, issueDateField(q), fetch)
+}
+
+// issuesResult is a page of issue search results, read by hand because the
+// github library doesn't read when a pull request merged
+type issuesResult struct {
+	Total int               `json:"total_count"`
+	Items []json.RawMessage `json:"items"`
+}
+
+// mergedResult is the part of an issue search result that says when a pull
+// request merged
+type mergedResult struct {
+	PullRequest *struct {
+		MergedAt *time.Time `json:"merged_at"`
+	} `json:"pull_request"`
+}
+
+// searchCommits returns every commit matching the query, splitting it into
+// date windows if it matches more than search will return
+func searchCommits(ctx context.Context, client *github.Client, q string) (Commits, error) {
+	fetch := func(ctx context.Context, q string, page int) ([]*github.CommitResult, int, int, error) {
+		opts := &github.SearchOptions{
+			ListOptions: github.ListOptions{
+				Page:    page,
+				PerPage: 100,
+			},
+		}
+
+		var result *github.CommitsSearchResult
+		response, err := withRetry(ctx, func() (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			result, resp, err = client.Search.Commits(ctx, q, opts)
+			return resp, err
+		})
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("github: could not search commits: %s", err)
+		}
+
+		return result.Commits, result.GetTotal(), response.NextPage, nil
+	}
 
+	return paginate(ctx, q, "committer-date", fetch)
 }
@@ -1,11 +1,20 @@
 package github
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-github/github"
 	"github.com/stretchr/testify/assert"
+	"github.com/tpryan/work"
 	"github.com/tpryan/work/artifact"
 )
 
@@ -46,3 +55,681 @@ func TestGHIssuesArtifacts(t *testing.T) {
 		})
 	}
 }
+
+func TestGHIssuesArtifactsAs(t *testing.T) {
+
+	title := "title"
+	createdAt := time.Date(2023, 8, 20, 0, 0, 0, 0, time.UTC)
+	closedAt := time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC)
+	u := "https://api.github.com/repos/tpryan/work/issues/1"
+
+	tests := map[string]struct {
+		in   Issues
+		typ  string
+		role string
+		want artifact.Artifacts
+	}{
+		"pullrequest": {
+			in: Issues{
+				&github.Issue{
+					Title:            &title,
+					ClosedAt:         &closedAt,
+					URL:              &u,
+					PullRequestLinks: &github.PullRequestLinks{},
+				},
+			},
+			role: RoleReviewer,
+			want: artifact.Artifacts{
+				artifact.Artifact{
+					Title:       title,
+					Role:        RoleReviewer,
+					Type:        TypePullRequest,
+					Link:        "https://github.com/tpryan/work/issues/1",
+					ShippedDate: closedAt,
+				},
+			},
+		},
+		"openissue": {
+			in: Issues{
+				&github.Issue{
+					Title:     &title,
+					CreatedAt: &createdAt,
+					URL:       &u,
+				},
+			},
+			role: RoleAuthor,
+			want: artifact.Artifacts{
+				artifact.Artifact{
+					Title:       title,
+					Role:        RoleAuthor,
+					Type:        TypeIssue,
+					Link:        "https://github.com/tpryan/work/issues/1",
+					ShippedDate: createdAt,
+				},
+			},
+		},
+		"enterprise": {
+			in: Issues{
+				&github.Issue{
+					Title:    &title,
+					ClosedAt: &closedAt,
+					URL:      github.String("https://github.example.com/api/v3/repos/o/r/issues/2"),
+					HTMLURL:  github.String("https://github.example.com/o/r/pull/2"),
+				},
+			},
+			role: RoleAuthor,
+			want: artifact.Artifacts{
+				artifact.Artifact{
+					Title:       title,
+					Role:        RoleAuthor,
+					Type:        TypeIssue,
+					Link:        "https://github.example.com/o/r/pull/2",
+					ShippedDate: closedAt,
+				},
+			},
+		},
+		"fixedtype": {
+			in: Issues{
+				&github.Issue{
+					Title:    &title,
+					ClosedAt: &closedAt,
+					URL:      &u,
+				},
+			},
+			typ:  "Bug",
+			role: RoleAssignee,
+			want: artifact.Artifacts{
+				artifact.Artifact{
+					Title:       title,
+					Role:        RoleAssignee,
+					Type:        "Bug",
+					Link:        "https://github.com/tpryan/work/issues/1",
+					ShippedDate: closedAt,
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tc.in.ArtifactsAs(tc.typ, tc.role)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGHCommitsArtifactsAs(t *testing.T) {
+	msg := "Fix the thing\n\nLonger description"
+	date := time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC)
+	u := "https://github.com/tpryan/work/commit/abc123"
+
+	in := Commits{
+		&github.CommitResult{
+			HTMLURL: &u,
+			Commit: &github.Commit{
+				Message:   &msg,
+				Committer: &github.CommitAuthor{Date: &date},
+			},
+		},
+	}
+
+	want := artifact.Artifacts{
+		artifact.Artifact{
+			Title:       "Fix the thing",
+			Role:        RoleAuthor,
+			Type:        TypeCommit,
+			Link:        u,
+			ShippedDate: date,
+		},
+	}
+
+	assert.Equal(t, want, in.ArtifactsAs("", RoleAuthor))
+}
+
+func TestGHSourceCollect(t *testing.T) {
+	queries := []string{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/search/issues":
+			fmt.Fprint(w, `{"total_count":1,"items":[{"title":"PR","url":"https://api.github.com/repos/o/r/issues/1","closed_at":"2023-08-21T00:00:00Z","pull_request":{}}]}`)
+		case "/search/commits":
+			fmt.Fprint(w, `{"total_count":1,"items":[{"html_url":"https://github.com/o/r/commit/abc","commit":{"message":"Commit","committer":{"date":"2023-08-22T00:00:00Z"}}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewSource(work.SourceConfig{
+		Name: "Github",
+		Github: work.GithubConfig{
+			User: "tpryan",
+			Queries: []work.GithubQuery{
+				{Query: "reviewed-by:{user} is:pr is:merged", Role: RoleReviewer},
+				{Query: "author:{user}", Kind: KindCommits, Role: RoleAuthor},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	s.client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	got, err := s.Collect(context.Background(), work.Criteria{})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	assert.Equal(t, []string{"reviewed-by:tpryan is:pr is:merged", "author:tpryan"}, queries)
+	assert.Equal(t, artifact.Artifacts{
+		{Title: "PR", Type: TypePullRequest, Role: RoleReviewer, Link: "https://github.com/o/r/issues/1", ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC)},
+		{Title: "Commit", Type: TypeCommit, Role: RoleAuthor, Link: "https://github.com/o/r/commit/abc", ShippedDate: time.Date(2023, 8, 22, 0, 0, 0, 0, time.UTC)},
+	}, got)
+
+	s.queries = []work.GithubQuery{{Query: "x", Kind: "gists"}}
+	_, err = s.Collect(context.Background(), work.Criteria{})
+	assert.ErrorContains(t, err, "unknown query kind \"gists\"")
+}
+
+func TestGHToken(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("could not write token file: %s", err)
+	}
+
+	t.Setenv(TokenEnv, "from-default-env")
+	t.Setenv("WORK_GITHUB_TOKEN", "from-env")
+
+	tests := map[string]struct {
+		in     work.GithubConfig
+		want   string
+		errStr string
+	}{
+		"config": {
+			in:   work.GithubConfig{Token: "from-config", TokenFile: path},
+			want: "from-config",
+		},
+		"file": {
+			in:   work.GithubConfig{TokenFile: path, TokenEnv: "WORK_GITHUB_TOKEN"},
+			want: "from-file",
+		},
+		"env": {
+			in:   work.GithubConfig{TokenEnv: "WORK_GITHUB_TOKEN"},
+			want: "from-env",
+		},
+		"default": {
+			in:   work.GithubConfig{},
+			want: "from-default-env",
+		},
+		"missingfile": {
+			in:     work.GithubConfig{TokenFile: filepath.Join(dir, "missing")},
+			errStr: "couldn't read token file",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Token(tc.in)
+
+			if tc.errStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+					t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGHSearchRateLimit(t *testing.T) {
+	calls := 0
+	// the reset is in the past so the client's own rate limit check lets the
+	// retry through without the test having to wait
+	reset := time.Now().Add(-time.Minute)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+
+		switch calls {
+		case 1:
+			w.Header().Set("X-RateLimit-Limit", "30")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"API rate limit exceeded for 127.0.0.1."}`)
+		case 2:
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have triggered an abuse detection mechanism","documentation_url":"https://developer.github.com/v3/#abuse-rate-limits"}`)
+		default:
+			fmt.Fprint(w, `{"total_count":1,"items":[{"title":"PR","url":"https://api.github.com/repos/o/r/issues/1"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	waits := []time.Duration{}
+	orig := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	defer func() { sleep = orig }()
+
+	client := NewClient(context.Background(), "")
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	got, err := searchIssues(context.Background(), client, "author:tpryan")
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	assert.Equal(t, 1, len(got))
+	assert.Equal(t, 3, calls)
+	if assert.Equal(t, 2, len(waits)) {
+		assert.Equal(t, time.Second, waits[0])
+		assert.Equal(t, 30*time.Second, waits[1])
+	}
+}
+
+func TestGHSourceEnterprise(t *testing.T) {
+	paths := []string{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"total_count":1,"items":[{"title":"PR","url":"https://ghe/api/v3/repos/o/r/issues/1","html_url":"https://ghe/o/r/pull/1","pull_request":{}}]}`)
+	}))
+	defer srv.Close()
+
+	s, err := NewSource(work.SourceConfig{
+		Name: "Github",
+		Github: work.GithubConfig{
+			User:    "tpryan",
+			BaseURL: srv.URL + "/api/v3",
+			Queries: []work.GithubQuery{{Query: "author:{user} is:pr is:merged", Role: RoleAuthor}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	got, err := s.Collect(context.Background(), work.Criteria{})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	assert.Equal(t, []string{"/api/v3/search/issues"}, paths)
+	assert.Equal(t, "https://ghe/o/r/pull/1", got[0].Link)
+
+	_, err = NewSource(work.SourceConfig{Github: work.GithubConfig{BaseURL: "://bad"}})
+	assert.ErrorContains(t, err, "invalid enterprise url")
+}
+
+func TestNewSourceQueries(t *testing.T) {
+	tests := map[string]struct {
+		in     work.GithubConfig
+		want   []string
+		errStr string
+	}{
+		"default": {
+			in:   work.GithubConfig{User: "tpryan"},
+			want: []string{"author:{user} is:pr state:closed"},
+		},
+		"include": {
+			in: work.GithubConfig{User: "tpryan", Include: []string{"Reviews", "commits"}},
+			want: []string{
+				"author:{user} is:pr state:closed",
+				"reviewed-by:{user} -author:{user} is:pr state:closed",
+				"author:{user}",
+			},
+		},
+		"custom": {
+			in: work.GithubConfig{
+				User:    "tpryan",
+				Queries: []work.GithubQuery{{Query: "author:{user} is:pr"}},
+				Include: []string{"assigned"},
+			},
+			want: []string{"author:{user} is:pr", "assignee:{user} is:issue state:closed"},
+		},
+		"unknown": {
+			in:     work.GithubConfig{User: "tpryan", Include: []string{"stars"}},
+			errStr: "unknown queries \"stars\"",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewSource(work.SourceConfig{Name: "Github", Github: tc.in})
+			if tc.errStr != "" {
+				assert.ErrorContains(t, err, tc.errStr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			got := []string{}
+			for _, q := range s.queries {
+				got = append(got, q.Query)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGHPaginate(t *testing.T) {
+	orig := now
+	now = func() time.Time { return searchStart.AddDate(0, 0, 7).Add(5 * time.Hour) }
+	defer func() { now = orig }()
+
+	queries := []string{}
+
+	// every day has 600 matches, so only single day windows fit under the cap
+	fetch := func(ctx context.Context, q string, page int) ([]string, int, int, error) {
+		queries = append(queries, fmt.Sprintf("%s #%d", q, page))
+
+		_, window, ok := strings.Cut(q, "closed:")
+		if !ok {
+			return []string{"unsplit"}, 4800, 2, nil
+		}
+
+		from, to, _ := strings.Cut(window, "..")
+		start, _ := time.Parse("2006-01-02", from)
+		end, _ := time.Parse("2006-01-02", to)
+		total := 600 * (int(end.Sub(start)/day) + 1)
+
+		next := 0
+		if page == 1 {
+			next = 2
+		}
+
+		return []string{fmt.Sprintf("%s p%d", from, page)}, total, next, nil
+	}
+
+	got, err := paginate(context.Background(), "author:tpryan state:closed", "closed", fetch)
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	want := []string{}
+	for i := 0; i < 8; i++ {
+		d := searchStart.AddDate(0, 0, i).Format("2006-01-02")
+		want = append(want, d+" p1", d+" p2")
+	}
+	assert.Equal(t, want, got)
+	assert.Equal(t, "author:tpryan state:closed #1", queries[0])
+	assert.Contains(t, queries, "author:tpryan state:closed closed:2008-01-01..2008-01-08 #1")
+
+	small := func(ctx context.Context, q string, page int) ([]string, int, int, error) {
+		if page == 1 {
+			return []string{"a"}, 2, 2, nil
+		}
+		return []string{"b"}, 2, 0, nil
+	}
+
+	got, err = paginate(context.Background(), "author:tpryan", "created", small)
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	assert.Equal(t, []string{"a", "b"}, got)
+}
+
+func TestGHIssueDateField(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want string
+	}{
+		"closed": {in: "author:tpryan is:pr state:closed", want: "closed"},
+		"merged": {in: "author:tpryan is:pr is:merged", want: "closed"},
+		"open":   {in: "author:tpryan is:issue", want: "created"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, issueDateField(tc.in))
+		})
+	}
+}
+
+func TestGHSourceMerges(t *testing.T) {
+	merged := `{"total_count":1,"items":[{"number":1,"state":"closed","title":"Merged","url":"https://api.github.com/repos/o/r/issues/1","html_url":"https://github.com/o/r/pull/1","repository_url":"https://api.github.com/repos/o/r","closed_at":"2023-08-21T00:05:00Z","pull_request":{"merged_at":"2023-08-21T00:00:00Z"}}]}`
+	unmerged := `{"total_count":2,"items":[` +
+		`{"number":2,"state":"closed","title":"Abandoned","url":"https://api.github.com/repos/o/r/issues/2","html_url":"https://github.com/o/r/pull/2","repository_url":"https://api.github.com/repos/o/r","closed_at":"2023-08-23T00:00:00Z","pull_request":{}},` +
+		`{"number":4,"state":"open","title":"Open","url":"https://api.github.com/repos/o/r/issues/4","html_url":"https://github.com/o/r/pull/4","repository_url":"https://api.github.com/repos/o/r","created_at":"2023-08-25T00:00:00Z","pull_request":{}}]}`
+	issues := `{"total_count":1,"items":[{"number":3,"state":"closed","title":"Issue","url":"https://api.github.com/repos/o/r/issues/3","html_url":"https://github.com/o/r/issues/3","repository_url":"https://api.github.com/repos/o/r","closed_at":"2023-08-24T00:00:00Z"}]}`
+
+	searches := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/search/issues" {
+			http.NotFound(w, r)
+			return
+		}
+
+		q := r.URL.Query().Get("q")
+		searches = append(searches, q)
+		switch {
+		case strings.HasSuffix(q, " is:merged"):
+			fmt.Fprint(w, merged)
+		case strings.HasSuffix(q, " is:unmerged"):
+			fmt.Fprint(w, unmerged)
+		case strings.HasSuffix(q, " is:issue"):
+			fmt.Fprint(w, issues)
+		default:
+			fmt.Fprint(w, `{"total_count":0,"items":[]}`)
+		}
+	}))
+	defer srv.Close()
+
+	mergedArt := artifact.Artifact{Title: "Merged", Type: TypePullRequest, Role: RoleAuthor, Link: "https://github.com/o/r/pull/1", ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC)}
+	openArt := artifact.Artifact{Title: "Open", Type: TypePullRequest, Role: RoleAuthor, Link: "https://github.com/o/r/pull/4", ShippedDate: time.Date(2023, 8, 25, 0, 0, 0, 0, time.UTC)}
+	issueArt := artifact.Artifact{Title: "Issue", Type: TypeIssue, Role: RoleAuthor, Link: "https://github.com/o/r/issues/3", ShippedDate: time.Date(2023, 8, 24, 0, 0, 0, 0, time.UTC)}
+
+	tests := map[string]struct {
+		in           work.GithubConfig
+		query        string
+		typ          string
+		want         artifact.Artifacts
+		wantSearches []string
+	}{
+		"default": {
+			query: "author:{user}",
+			want: artifact.Artifacts{
+				mergedArt,
+				{Title: "Abandoned", Type: TypeUnmergedPullRequest, Role: RoleAuthor, Link: "https://github.com/o/r/pull/2", ShippedDate: time.Date(2023, 8, 23, 0, 0, 0, 0, time.UTC)},
+				openArt,
+				issueArt,
+			},
+			wantSearches: []string{"author:tpryan is:merged", "author:tpryan is:unmerged", "author:tpryan is:issue"},
+		},
+		"type": {
+			in:    work.GithubConfig{UnmergedType: "Closed PR"},
+			query: "author:{user} is:pr",
+			want: artifact.Artifacts{
+				mergedArt,
+				{Title: "Abandoned", Type: "Closed PR", Role: RoleAuthor, Link: "https://github.com/o/r/pull/2", ShippedDate: time.Date(2023, 8, 23, 0, 0, 0, 0, time.UTC)},
+				openArt,
+			},
+			wantSearches: []string{"author:tpryan is:pr is:merged", "author:tpryan is:pr is:unmerged"},
+		},
+		"drop": {
+			in:           work.GithubConfig{DropUnmerged: true},
+			query:        "author:{user} is:pr",
+			want:         artifact.Artifacts{mergedArt, openArt},
+			wantSearches: []string{"author:tpryan is:pr is:merged", "author:tpryan is:pr is:unmerged"},
+		},
+		"dropclosed": {
+			in:           work.GithubConfig{DropUnmerged: true},
+			query:        "author:{user} is:pr state:closed",
+			want:         artifact.Artifacts{mergedArt},
+			wantSearches: []string{"author:tpryan is:pr state:closed is:merged"},
+		},
+		"fixedtype": {
+			in:           work.GithubConfig{},
+			query:        "label:bug",
+			typ:          "Bug",
+			want:         artifact.Artifacts{},
+			wantSearches: []string{"label:bug"},
+		},
+		"fixedtypeprs": {
+			query: "author:{user} is:pr",
+			typ:   "Change",
+			want: artifact.Artifacts{
+				{Title: "Merged", Type: "Change", Role: RoleAuthor, Link: "https://github.com/o/r/pull/1", ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC)},
+				{Title: "Abandoned", Type: TypeUnmergedPullRequest, Role: RoleAuthor, Link: "https://github.com/o/r/pull/2", ShippedDate: time.Date(2023, 8, 23, 0, 0, 0, 0, time.UTC)},
+				{Title: "Open", Type: "Change", Role: RoleAuthor, Link: "https://github.com/o/r/pull/4", ShippedDate: time.Date(2023, 8, 25, 0, 0, 0, 0, time.UTC)},
+			},
+			wantSearches: []string{"author:tpryan is:pr is:merged", "author:tpryan is:pr is:unmerged"},
+		},
+		"issuesonly": {
+			query:        "author:{user} is:issue",
+			want:         artifact.Artifacts{issueArt},
+			wantSearches: []string{"author:tpryan is:issue"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			searches = []string{}
+			cfg := tc.in
+			cfg.User = "tpryan"
+			cfg.Queries = []work.GithubQuery{{Query: tc.query, Type: tc.typ, Role: RoleAuthor}}
+
+			s, err := NewSource(work.SourceConfig{Name: "Github", Github: cfg})
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+			s.client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+			got, err := s.Collect(context.Background(), work.Criteria{})
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantSearches, searches)
+		})
+	}
+}
+
+func TestGHRepository(t *testing.T) {
+	tests := map[string]struct {
+		in        string
+		wantOwner string
+		wantRepo  string
+		errStr    string
+	}{
+		"github":     {in: "https://api.github.com/repos/o/r", wantOwner: "o", wantRepo: "r"},
+		"enterprise": {in: "https://ghe/api/v3/repos/o/r/", wantOwner: "o", wantRepo: "r"},
+		"bad":        {in: "https://example.com/o/r", errStr: "unexpected repository url"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			owner, repo, err := repository(tc.in)
+
+			if tc.errStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+					t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.Equal(t, tc.wantOwner, owner)
+			assert.Equal(t, tc.wantRepo, repo)
+		})
+	}
+}
+
+func TestGHSourceProjects(t *testing.T) {
+	issue := func(n int, repo, label string) string {
+		labels := "[]"
+		if label != "" {
+			labels = fmt.Sprintf(`[{"name":%q}]`, label)
+		}
+		return fmt.Sprintf(`{"number":%d,"state":"open","title":"%d","url":"https://api.github.com/repos/%s/issues/%d","html_url":"https://github.com/%s/issues/%d","repository_url":"https://api.github.com/repos/%s","labels":%s}`,
+			n, n, repo, n, repo, n, repo, labels)
+	}
+
+	topicCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/search/issues":
+			fmt.Fprintf(w, `{"total_count":7,"items":[%s,%s,%s,%s,%s,%s,%s]}`,
+				issue(1, "o/r", ""),
+				issue(2, "o/other", ""),
+				issue(3, "x/y", ""),
+				issue(4, "x/z", "docs"),
+				issue(5, "x/y", ""),
+				issue(6, "gone/repo", ""),
+				issue(7, "gone/repo", ""),
+			)
+		case "/search/commits":
+			fmt.Fprint(w, `{"total_count":1,"items":[{"html_url":"https://github.com/o/r/commit/abc","commit":{"message":"Commit"},"repository":{"full_name":"o/r"}}]}`)
+		case "/repos/x/y/topics":
+			topicCalls++
+			fmt.Fprint(w, `{"names":["kubernetes"]}`)
+		case "/repos/x/z/topics", "/repos/o/other/topics":
+			topicCalls++
+			fmt.Fprint(w, `{"names":[]}`)
+		case "/repos/gone/repo/topics":
+			topicCalls++
+			http.NotFound(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewSource(work.SourceConfig{
+		Name: "Github",
+		Github: work.GithubConfig{
+			User: "tpryan",
+			Queries: []work.GithubQuery{
+				{Query: "author:{user} is:issue", Role: RoleAuthor},
+				{Query: "author:{user}", Kind: KindCommits, Role: RoleAuthor},
+			},
+			Projects: []work.GithubProject{
+				{Project: "Work", Subproject: "Core", Repos: []string{"o/r"}},
+				{Project: "Docs", Labels: []string{"DOCS"}},
+				{Project: "K8s", Topics: []string{"kubernetes"}},
+				{Project: "Owner", Repos: []string{"o/*"}},
+				{Project: "Gone", Repos: []string{"gone/repo"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	s.client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	got, err := s.Collect(context.Background(), work.Criteria{})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	projects := []string{}
+	for _, art := range got {
+		projects = append(projects, art.Project+"/"+art.Subproject)
+	}
+
+	assert.Equal(t, []string{"Work/Core", "Owner/", "K8s/", "Docs/", "K8s/", "Gone/", "Gone/", "Work/Core"}, projects)
+	assert.Equal(t, 3, topicCalls, "topics should be fetched once per repository")
+}
@@ -0,0 +1,131 @@
+package github
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/go-github/github"
+	"github.com/tpryan/work"
+	"github.com/tpryan/work/artifact"
+)
+
+// project returns the first configured project that the work matches, by
+// repository, repository topic or label
+func (s *Source) project(ctx context.Context, repo string, labels []string) (work.GithubProject, bool) {
+	for _, p := range s.projects {
+		if matchRepo(p.Repos, repo) || matchAny(p.Labels, labels) {
+			return p, true
+		}
+
+		if len(p.Topics) == 0 || repo == "" {
+			continue
+		}
+
+		if matchAny(p.Topics, s.topics(ctx, repo)) {
+			return p, true
+		}
+	}
+
+	return work.GithubProject{}, false
+}
+
+// classify fills in the Project and Subproject of an artifact from the
+// configured projects, leaving it alone if none match
+func (s *Source) classify(ctx context.Context, art artifact.Artifact, repo string, labels []string) artifact.Artifact {
+	if len(s.projects) == 0 || art.Project != "" {
+		return art
+	}
+
+	if p, ok := s.project(ctx, repo, labels); ok {
+		art.Project = p.Project
+		art.Subproject = p.Subproject
+	}
+
+	return art
+}
+
+// classifyIssues classifies each artifact by the repository and labels of
+// the issue it came from. arts must line up with issues.
+func (s *Source) classifyIssues(ctx context.Context, issues Issues, arts artifact.Artifacts) artifact.Artifacts {
+	for i, issue := range issues {
+		repo := ""
+		if owner, name, err := repository(issue.GetRepositoryURL()); err == nil {
+			repo = owner + "/" + name
+		}
+
+		labels := []string{}
+		for _, l := range issue.Labels {
+			labels = append(labels, l.GetName())
+		}
+
+		arts[i] = s.classify(ctx, arts[i], repo, labels)
+	}
+
+	return arts
+}
+
+// classifyCommits classifies each artifact by the repository of the commit
+// it came from. arts must line up with commits.
+func (s *Source) classifyCommits(ctx context.Context, commits Commits, arts artifact.Artifacts) artifact.Artifacts {
+	for i, commit := range commits {
+		arts[i] = s.classify(ctx, arts[i], commit.GetRepository().GetFullName(), nil)
+	}
+
+	return arts
+}
+
+// topics fetches the topics of a repository, remembering them for the next
+// piece of work in the same repository. A repository whose topics can't be
+// read, such as one that was deleted, renamed or made private, is treated as
+// having none so its work can still be classified by repository and label.
+func (s *Source) topics(ctx context.Context, repo string) []string {
+	if topics, ok := s.repoTopics[repo]; ok {
+		return topics
+	}
+
+	owner, name, _ := strings.Cut(repo, "/")
+
+	var topics []string
+	_, err := withRetry(ctx, func() (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		topics, resp, err = s.client.Repositories.ListAllTopics(ctx, owner, name)
+		return resp, err
+	})
+	if err != nil {
+		log.Warnf("github: could not get topics for %s, classifying it without them: %s", repo, err)
+		topics = []string{}
+	}
+
+	s.repoTopics[repo] = topics
+	return topics
+}
+
+// matchRepo reports whether a repository is in a list, where owner/* stands
+// for every repository of that owner
+func matchRepo(list []string, repo string) bool {
+	if repo == "" {
+		return false
+	}
+
+	owner, _, _ := strings.Cut(repo, "/")
+	for _, r := range list {
+		if strings.EqualFold(r, repo) || strings.EqualFold(r, owner+"/*") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny reports whether any of the values is in the list, ignoring case
+func matchAny(list, values []string) bool {
+	for _, l := range list {
+		for _, v := range values {
+			if strings.EqualFold(l, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/tpryan/work"
+	"github.com/tpryan/work/artifact"
+)
+
+// DefaultQueries are run for sources that don't list their own: the closed
+// pull requests the user authored
+var DefaultQueries = []work.GithubQuery{
+	{Query: "author:{user} is:pr state:closed", Type: TypePullRequest, Role: RoleAuthor},
+}
+
+// IncludeQueries are the extra queries a source can opt into by name,
+// alongside the default ones
+var IncludeQueries = map[string]work.GithubQuery{
+	"reviews":  {Query: "reviewed-by:{user} -author:{user} is:pr state:closed", Type: TypePullRequest, Role: RoleReviewer},
+	"issues":   {Query: "author:{user} is:issue state:closed", Type: TypeIssue, Role: RoleAuthor},
+	"assigned": {Query: "assignee:{user} is:issue state:closed", Type: TypeIssue, Role: RoleAssignee},
+	"commits":  {Query: "author:{user}", Kind: KindCommits, Type: TypeCommit, Role: RoleAuthor},
+}
+
+// Source collects the pull requests, issues and commits of a github user
+type Source struct {
+	name         string
+	user         string
+	queries      []work.GithubQuery
+	unmergedType string
+	dropUnmerged bool
+	projects     []work.GithubProject
+	client       *github.Client
+	repoTopics   map[string][]string
+}
+
+// NewSource returns a github source for the input configuration, signed in
+// with the configured token if there is one, talking to GitHub Enterprise if
+// a base URL is configured
+func NewSource(cfg work.SourceConfig) (*Source, error) {
+	queries := append([]work.GithubQuery{}, cfg.Github.Queries...)
+	if len(queries) == 0 {
+		queries = append(queries, DefaultQueries...)
+	}
+
+	for _, name := range cfg.Github.Include {
+		q, ok := IncludeQueries[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("github: source %s includes unknown queries %q", cfg.Name, name)
+		}
+		queries = append(queries, q)
+	}
+
+	token, err := Token(cfg.Github)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := NewEnterpriseClient(context.Background(), token, cfg.Github.BaseURL, cfg.Github.UploadURL)
+	if err != nil {
+		return nil, err
+	}
+
+	unmerged := cfg.Github.UnmergedType
+	if unmerged == "" {
+		unmerged = TypeUnmergedPullRequest
+	}
+
+	return &Source{
+		name:         cfg.Name,
+		user:         cfg.Github.User,
+		queries:      queries,
+		unmergedType: unmerged,
+		dropUnmerged: cfg.Github.DropUnmerged,
+		projects:     cfg.Github.Projects,
+		client:       client,
+		repoTopics:   map[string][]string{},
+	}, nil
+}
+
+// Name returns the name of the source
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Collect runs each of the configured queries for the configured user
+func (s *Source) Collect(ctx context.Context, criteria work.Criteria) (artifact.Artifacts, error) {
+	if s.user == "" {
+		return nil, fmt.Errorf("github: source %s has no user configured", s.name)
+	}
+
+	result := artifact.Artifacts{}
+
+	for _, query := range s.queries {
+		arts, err := s.search(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("could not get %s: %w", s.expand(query.Query), err)
+		}
+		result = append(result, arts...)
+	}
+
+	return result, nil
+}
+
+func (s *Source) search(ctx context.Context, query work.GithubQuery) (artifact.Artifacts, error) {
+	q := s.expand(query.Query)
+
+	switch query.Kind {
+	case "", KindIssues:
+		result := artifact.Artifacts{}
+		for _, p := range s.split(q, query.Type) {
+			issues, err := searchIssues(ctx, s.client, p.query)
+			if err != nil {
+				return nil, err
+			}
+			arts := s.classifyIssues(ctx, issues, issues.ArtifactsAs(query.Type, query.Role))
+			if p.unmerged {
+				arts = s.unmerged(issues, arts)
+			}
+			result = append(result, arts...)
+		}
+		return result, nil
+	case KindCommits:
+		commits, err := searchCommits(ctx, s.client, q)
+		if err != nil {
+			return nil, err
+		}
+		return s.classifyCommits(ctx, commits, commits.ArtifactsAs(query.Type, query.Role)), nil
+	}
+
+	return nil, fmt.Errorf("github: unknown query kind %q", query.Kind)
+}
+
+// part is one of the searches a query is split into
+type part struct {
+	query    string
+	unmerged bool
+}
+
+// split returns the searches an issues query is run as, so merged pull
+// requests can be told from unmerged ones without fetching each one: the
+// query limited to merged pull requests, then to unmerged ones, then, if it
+// can find issues too, to issues. The unmerged search is skipped when its
+// results would all be dropped. Queries that already pick issues or a merge
+// state are run as they are, as are queries with a fixed type that can find
+// issues, since everything they find is given that type.
+func (s *Source) split(q, typ string) []part {
+	prs := false
+	closed := false
+	for _, f := range strings.Fields(strings.ToLower(q)) {
+		switch f {
+		case "is:issue", "type:issue", "is:merged", "is:unmerged":
+			return []part{{query: q}}
+		case "is:pr", "type:pr":
+			prs = true
+		case "is:closed", "state:closed":
+			closed = true
+		}
+	}
+
+	if typ != "" && !prs {
+		return []part{{query: q}}
+	}
+
+	result := []part{{query: q + " is:merged"}}
+	if !(s.dropUnmerged && closed) {
+		result = append(result, part{query: q + " is:unmerged", unmerged: true})
+	}
+	if !prs {
+		result = append(result, part{query: q + " is:issue"})
+	}
+
+	return result
+}
+
+// unmerged gives the closed pull requests found by an unmerged search the
+// unmerged type, or drops them if the source is configured to. Open ones
+// are left alone. arts must line up with issues.
+func (s *Source) unmerged(issues Issues, arts artifact.Artifacts) artifact.Artifacts {
+	result := artifact.Artifacts{}
+
+	for i, issue := range issues {
+		art := arts[i]
+
+		if issue.GetState() == "closed" {
+			if s.dropUnmerged {
+				continue
+			}
+			art.Type = s.unmergedType
+		}
+
+		result = append(result, art)
+	}
+
+	return result
+}
+
+// repository returns the owner and name of a repository from its API URL,
+// which ends in /repos/{owner}/{repo}
+func repository(u string) (string, string, error) {
+	parts := strings.Split(strings.TrimSuffix(u, "/"), "/")
+	if len(parts) < 3 || parts[len(parts)-3] != "repos" {
+		return "", "", fmt.Errorf("github: unexpected repository url %q", u)
+	}
+
+	return parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
+// expand fills the configured user into a query
+func (s *Source) expand(q string) string {
+	return strings.ReplaceAll(q, "{user}", s.user)
+}
@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// searchCap is the most results github search will return for one query,
+// however many pages are asked for
+const searchCap = 1000
+
+// searchStart is the earliest date a query is split from. Nothing on github
+// is older.
+var searchStart = time.Date(2008, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// now is the latest date a query is split to. It is a variable so tests can
+// pin it.
+var now = time.Now
+
+const day = 24 * time.Hour
+
+// fetcher gets a single page of search results, along with the total number
+// of matches and the next page, which is 0 on the last page
+type fetcher[T any] func(ctx context.Context, q string, page int) ([]T, int, int, error)
+
+// paginate returns every result of a search. Queries that match more than
+// search will return are split into date windows on the input field, which
+// are split again until each fits under the cap.
+func paginate[T any](ctx context.Context, q, field string, fetch fetcher[T]) ([]T, error) {
+	results, total, next, err := fetch(ctx, q, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if total <= searchCap {
+		return rest(ctx, q, results, next, fetch)
+	}
+
+	log.Warnf("github: %q matched %d results, more than the %d search returns, splitting it by %s", q, total, searchCap, field)
+
+	from := searchStart
+	to := now().UTC().Truncate(day)
+
+	return window(ctx, q, field, from, to, fetch)
+}
+
+// window searches the days from and to, inclusive, halving the window until
+// its results fit under the cap
+func window[T any](ctx context.Context, q, field string, from, to time.Time, fetch fetcher[T]) ([]T, error) {
+	wq := fmt.Sprintf("%s %s:%s..%s", q, field, from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	results, total, next, err := fetch(ctx, wq, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	days := int(to.Sub(from) / day)
+
+	if total > searchCap && days > 0 {
+		mid := from.AddDate(0, 0, days/2)
+
+		left, err := window(ctx, q, field, from, mid, fetch)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := window(ctx, q, field, mid.AddDate(0, 0, 1), to, fetch)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(left, right...), nil
+	}
+
+	if total > searchCap {
+		log.Warnf("github: %q matched %d results in a single day, only the first %d will be collected", wq, total, searchCap)
+	}
+
+	return rest(ctx, wq, results, next, fetch)
+}
+
+// rest collects the pages after the first
+func rest[T any](ctx context.Context, q string, results []T, next int, fetch fetcher[T]) ([]T, error) {
+	for next > 0 {
+		page, _, n, err := fetch(ctx, q, next)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, page...)
+		next = n
+	}
+
+	return results, nil
+}
+
+// issueDateField picks the date to split an issue search on. Searches for
+// closed issues and pull requests are split by when they closed, so each
+// window is about the work it shipped; anything else by when it was created.
+func issueDateField(q string) string {
+	for _, term := range strings.Fields(q) {
+		switch term {
+		case "state:closed", "is:closed", "is:merged":
+			return "closed"
+		}
+	}
+	return "created"
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/tpryan/work/artifact"
@@ -17,16 +16,62 @@ type Interfacer interface {
 	ToInterfaces() [][]interface{}
 }
 
+// Spreadsheets is the narrow set of spreadsheet operations GSheet relies on.
+// The Sheets API satisfies it through NewService, but any backend that
+// behaves like a spreadsheet can be swapped in.
+type Spreadsheets interface {
+	Get(ctx context.Context, id string, ranges []string, includeGridData bool) (*sheets.Spreadsheet, error)
+	BatchUpdate(ctx context.Context, id string, req *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error)
+	UpdateValues(ctx context.Context, id, rng string, vr *sheets.ValueRange, inputOption string) (*sheets.UpdateValuesResponse, error)
+	ClearValues(ctx context.Context, id, rng string) (*sheets.ClearValuesResponse, error)
+	BatchUpdateValues(ctx context.Context, id string, req *sheets.BatchUpdateValuesRequest) (*sheets.BatchUpdateValuesResponse, error)
+}
+
+// Service adapts a Sheets API client to the Spreadsheets interface
+type Service struct {
+	svc *sheets.Service
+}
+
+// NewService wraps a Sheets API client so it can back a GSheet
+func NewService(svc *sheets.Service) *Service {
+	return &Service{svc: svc}
+}
+
+// Get returns a spreadsheet, limited to the input ranges
+func (s *Service) Get(ctx context.Context, id string, ranges []string, includeGridData bool) (*sheets.Spreadsheet, error) {
+	return s.svc.Spreadsheets.Get(id).Ranges(ranges...).IncludeGridData(includeGridData).Context(ctx).Do()
+}
+
+// BatchUpdate applies a set of structural or formatting changes
+func (s *Service) BatchUpdate(ctx context.Context, id string, req *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error) {
+	return s.svc.Spreadsheets.BatchUpdate(id, req).Context(ctx).Do()
+}
+
+// UpdateValues writes values into a range
+func (s *Service) UpdateValues(ctx context.Context, id, rng string, vr *sheets.ValueRange, inputOption string) (*sheets.UpdateValuesResponse, error) {
+	return s.svc.Spreadsheets.Values.Update(id, rng, vr).ValueInputOption(inputOption).Context(ctx).Do()
+}
+
+// ClearValues removes the values from a range
+func (s *Service) ClearValues(ctx context.Context, id, rng string) (*sheets.ClearValuesResponse, error) {
+	return s.svc.Spreadsheets.Values.Clear(id, rng, &sheets.ClearValuesRequest{}).Context(ctx).Do()
+}
+
+// BatchUpdateValues writes values into several ranges at once
+func (s *Service) BatchUpdateValues(ctx context.Context, id string, req *sheets.BatchUpdateValuesRequest) (*sheets.BatchUpdateValuesResponse, error) {
+	return s.svc.Spreadsheets.Values.BatchUpdate(id, req).Context(ctx).Do()
+}
+
 // GSheet provides read/write access to a Google Sheet. Given the correctly
 // initialized service it basically turns a Gsheet into a datasource
 type GSheet struct {
-	svc *sheets.Service
+	svc Spreadsheets
 	id  string
 }
 
 // New returns a new GSheet object to act as a datasource
-func New(svc sheets.Service, sheetID string) GSheet {
-	g := GSheet{svc: &svc, id: sheetID}
+func New(svc Spreadsheets, sheetID string) GSheet {
+	g := GSheet{svc: svc, id: sheetID}
 	return g
 
 }
@@ -37,7 +82,7 @@ func (g *GSheet) SheetID(name string) (int64, error) {
 
 	ranges := []string{name}
 
-	resp, err := g.svc.Spreadsheets.Get(g.id).Ranges(ranges...).Context(context.Background()).Do()
+	resp, err := g.svc.Get(context.Background(), g.id, ranges, false)
 	if err != nil {
 		if strings.Contains(err.Error(), "Unable to parse range") {
 			return 0, errGSheetDoesNotExist
@@ -51,14 +96,11 @@ func (g *GSheet) SheetID(name string) (int64, error) {
 var errGSheetDoesNotExist = fmt.Errorf("sheets: input sheet does not exist")
 var errGSheetAlreadyExists = fmt.Errorf("sheets: input sheet already exists")
 
-// Clear removes all content from an input sheet name
+// Clear removes all content from an input sheet name, however many columns
+// it has
 func (g *GSheet) Clear(name string) error {
 
-	clearRange := fmt.Sprintf("%s!A:Z", name)
-
-	req := &sheets.ClearValuesRequest{}
-
-	if _, err := g.svc.Spreadsheets.Values.Clear(g.id, clearRange, req).Do(); err != nil {
+	if _, err := g.svc.ClearValues(context.Background(), g.id, name); err != nil {
 		if strings.Contains(err.Error(), "Unable to parse range") {
 			return errGSheetDoesNotExist
 		}
@@ -82,7 +124,7 @@ func (g *GSheet) Add(name string) error {
 			}},
 	}
 
-	if _, err := g.svc.Spreadsheets.BatchUpdate(g.id, rbb).Do(); err != nil {
+	if _, err := g.svc.BatchUpdate(context.Background(), g.id, rbb); err != nil {
 		if strings.Contains(err.Error(), fmt.Sprintf("A sheet with the name \"%s\" already exists", name)) {
 			return errGSheetAlreadyExists
 		}
@@ -109,15 +151,15 @@ func (g *GSheet) Delete(name string) error {
 			}},
 	}
 
-	if _, err := g.svc.Spreadsheets.BatchUpdate(g.id, rbb).Do(); err != nil {
+	if _, err := g.svc.BatchUpdate(context.Background(), g.id, rbb); err != nil {
 		return fmt.Errorf("sheets: failed to delete sheet %s", err)
 	}
 	return nil
 }
 
 // FormatSheet creates a set of batch requests that will format a sheet for
-// displaying artifacts
-func (g *GSheet) FormatSheet(id int64) []*sheets.Request {
+// displaying artifacts in the input number of columns
+func (g *GSheet) FormatSheet(id int64, columns int64) []*sheets.Request {
 
 	batchreq := &sheets.BatchUpdateSpreadsheetRequest{
 		Requests: []*sheets.Request{
@@ -196,7 +238,7 @@ func (g *GSheet) FormatSheet(id int64) []*sheets.Request {
 						SheetId:    id,
 						Dimension:  "COLUMNS",
 						StartIndex: 0,
-						EndIndex:   6,
+						EndIndex:   columns,
 					},
 				},
 			},
@@ -209,96 +251,122 @@ func (g *GSheet) FormatSheet(id int64) []*sheets.Request {
 // FormatRows generates batch requests to format individual rows of a row
 // where the rows consist of set of Artifacts
 func (g *GSheet) FormatRows(id int64, a artifact.Artifacts) []*sheets.Request {
+	requests := []*sheets.Request{}
+	for i, art := range a {
+		requests = append(requests, formatRow(id, int64(i)+1, art)...)
+	}
+	return requests
+}
+
+// formatRow highlights the row of a sheet holding an artifact, by index, if
+// the artifact is missing a type, project or subproject
+func formatRow(id int64, row int64, art artifact.Artifact) []*sheets.Request {
 
 	batchreq := &sheets.BatchUpdateSpreadsheetRequest{
 		Requests: []*sheets.Request{},
 	}
 
-	for i, art := range a {
-
-		if art.Subproject == "" {
-			req := &sheets.Request{
-				RepeatCell: &sheets.RepeatCellRequest{
-					Fields: "userEnteredFormat.backgroundColorStyle",
-					Range: &sheets.GridRange{
-						SheetId:          id,
-						StartColumnIndex: 0,
-						StartRowIndex:    int64(i) + 1,
-						EndRowIndex:      int64(i) + 2,
-					},
-					Cell: &sheets.CellData{
-						UserEnteredFormat: &sheets.CellFormat{
-							BackgroundColorStyle: &sheets.ColorStyle{
-								RgbColor: &sheets.Color{
-									Red:   1.0,
-									Blue:  .98,
-									Green: .98,
-								}},
-						},
+	if art.Subproject == "" {
+		req := &sheets.Request{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Fields: "userEnteredFormat.backgroundColorStyle",
+				Range: &sheets.GridRange{
+					SheetId:          id,
+					StartColumnIndex: 0,
+					StartRowIndex:    row,
+					EndRowIndex:      row + 1,
+				},
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						BackgroundColorStyle: &sheets.ColorStyle{
+							RgbColor: &sheets.Color{
+								Red:   1.0,
+								Blue:  .98,
+								Green: .98,
+							}},
 					},
 				},
-			}
-
-			batchreq.Requests = append(batchreq.Requests, req)
+			},
 		}
 
-		if art.Type == "" {
-			req := &sheets.Request{
-				RepeatCell: &sheets.RepeatCellRequest{
-					Fields: "userEnteredFormat.backgroundColorStyle",
-					Range: &sheets.GridRange{
-						SheetId:          id,
-						StartColumnIndex: 0,
-						StartRowIndex:    int64(i) + 1,
-						EndRowIndex:      int64(i) + 2,
-					},
-					Cell: &sheets.CellData{
-						UserEnteredFormat: &sheets.CellFormat{
-							BackgroundColorStyle: &sheets.ColorStyle{
-								RgbColor: &sheets.Color{
-									Red:   1.0,
-									Blue:  .50,
-									Green: .95,
-								}},
-						},
+		batchreq.Requests = append(batchreq.Requests, req)
+	}
+
+	if art.Type == "" {
+		req := &sheets.Request{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Fields: "userEnteredFormat.backgroundColorStyle",
+				Range: &sheets.GridRange{
+					SheetId:          id,
+					StartColumnIndex: 0,
+					StartRowIndex:    row,
+					EndRowIndex:      row + 1,
+				},
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						BackgroundColorStyle: &sheets.ColorStyle{
+							RgbColor: &sheets.Color{
+								Red:   1.0,
+								Blue:  .50,
+								Green: .95,
+							}},
 					},
 				},
-			}
-
-			batchreq.Requests = append(batchreq.Requests, req)
+			},
 		}
 
-		if art.Project == "" {
-			req := &sheets.Request{
-				RepeatCell: &sheets.RepeatCellRequest{
-					Fields: "userEnteredFormat.backgroundColorStyle",
-					Range: &sheets.GridRange{
-						SheetId:          id,
-						StartColumnIndex: 0,
-						StartRowIndex:    int64(i) + 1,
-						EndRowIndex:      int64(i) + 2,
-					},
-					Cell: &sheets.CellData{
-						UserEnteredFormat: &sheets.CellFormat{
-							BackgroundColorStyle: &sheets.ColorStyle{
-								RgbColor: &sheets.Color{
-									Red:   1.0,
-									Blue:  .90,
-									Green: .90,
-								}},
-						},
+		batchreq.Requests = append(batchreq.Requests, req)
+	}
+
+	if art.Project == "" {
+		req := &sheets.Request{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Fields: "userEnteredFormat.backgroundColorStyle",
+				Range: &sheets.GridRange{
+					SheetId:          id,
+					StartColumnIndex: 0,
+					StartRowIndex:    row,
+					EndRowIndex:      row + 1,
+				},
+				Cell: &sheets.CellData{
+					UserEnteredFormat: &sheets.CellFormat{
+						BackgroundColorStyle: &sheets.ColorStyle{
+							RgbColor: &sheets.Color{
+								Red:   1.0,
+								Blue:  .90,
+								Green: .90,
+							}},
 					},
 				},
-			}
-
-			batchreq.Requests = append(batchreq.Requests, req)
+			},
 		}
 
+		batchreq.Requests = append(batchreq.Requests, req)
 	}
 
 	return batchreq.Requests
 }
 
+// FormatRequests generates every batch request needed to reset and format a
+// sheet that holds the input Artifacts
+func (g *GSheet) FormatRequests(id int64, a artifact.Artifacts) []*sheets.Request {
+	requests := []*sheets.Request{
+		{
+			RepeatCell: &sheets.RepeatCellRequest{
+				Fields: "userEnteredFormat",
+				Range: &sheets.GridRange{
+					SheetId: id,
+				},
+			},
+		},
+	}
+
+	requests = append(requests, g.FormatSheet(id, int64(len(a.Columns())))...)
+	requests = append(requests, g.FormatRows(id, a)...)
+
+	return requests
+}
+
 // ToSheet sends an interface to the named Sheet
 func (g *GSheet) ToSheet(name string, i Interfacer) error {
 
@@ -327,28 +395,30 @@ func (g *GSheet) ToSheet(name string, i Interfacer) error {
 	}
 
 	batchreq := &sheets.BatchUpdateSpreadsheetRequest{
-		Requests: []*sheets.Request{
-			{
-				RepeatCell: &sheets.RepeatCellRequest{
-					Fields: "userEnteredFormat",
-					Range: &sheets.GridRange{
-						SheetId: id,
-					},
-				},
-			},
-		},
+		Requests: g.FormatRequests(id, i.(artifact.Artifacts)),
 	}
 
-	batchreq.Requests = append(batchreq.Requests, g.FormatSheet(id)...)
-	batchreq.Requests = append(batchreq.Requests, g.FormatRows(id, i.(artifact.Artifacts))...)
-
-	if _, err := g.svc.Spreadsheets.BatchUpdate(g.id, batchreq).Do(); err != nil {
+	if _, err := g.svc.BatchUpdate(context.Background(), g.id, batchreq); err != nil {
 		return fmt.Errorf("sheets: failed to apply formatting %s", err)
 	}
 
 	return nil
 }
 
+// Write replaces the contents of the named sheet with the input artifacts
+func (g *GSheet) Write(ctx context.Context, name string, a artifact.Artifacts) error {
+	return g.ToSheet(name, a)
+}
+
+// Read returns the artifacts currently in the named sheet. A sheet that
+// doesn't exist yet has none.
+func (g *GSheet) Read(ctx context.Context, name string) (artifact.Artifacts, error) {
+	if _, err := g.SheetID(name); err == errGSheetDoesNotExist {
+		return artifact.Artifacts{}, nil
+	}
+	return g.Artifacts(name)
+}
+
 // UpdateData inserts a given set of interfacer data into the spreadsheet in
 // sheet name
 func (g *GSheet) UpdateData(name string, i Interfacer) error {
@@ -358,7 +428,7 @@ func (g *GSheet) UpdateData(name string, i Interfacer) error {
 
 	r := fmt.Sprintf("%s!A%d:Z100000", name, 1)
 
-	if _, err := g.svc.Spreadsheets.Values.Update(g.id, r, &vr).ValueInputOption("USER_ENTERED").Do(); err != nil {
+	if _, err := g.svc.UpdateValues(context.Background(), g.id, r, &vr, "USER_ENTERED"); err != nil {
 		if strings.Contains(err.Error(), "Unable to parse range") {
 			return errGSheetDoesNotExist
 		}
@@ -368,12 +438,28 @@ func (g *GSheet) UpdateData(name string, i Interfacer) error {
 	return nil
 }
 
-// Artifacts returns a given sheet as Artifacts
+// Artifacts returns a given sheet as Artifacts. Columns are matched by their
+// header, so they can be in any order, and columns that aren't artifact
+// fields are read as custom attributes. Rows that can't be read are logged
+// and left out.
 func (g *GSheet) Artifacts(name string) (artifact.Artifacts, error) {
-	as := artifact.Artifacts{}
+	as, problems, err := g.Parse(name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range problems {
+		log.Warnf("sheets: skipping %s", p)
+	}
+
+	return as, nil
+}
+
+// rows returns the raw grid data of a given sheet
+func (g *GSheet) rows(name string) ([]*sheets.RowData, error) {
 	ranges := []string{name}
 
-	resp, err := g.svc.Spreadsheets.Get(g.id).Ranges(ranges...).IncludeGridData(true).Do()
+	resp, err := g.svc.Get(context.Background(), g.id, ranges, true)
 	if err != nil {
 		if strings.Contains(err.Error(), "Unable to parse range") {
 			return nil, errGSheetDoesNotExist
@@ -382,32 +468,12 @@ func (g *GSheet) Artifacts(name string) (artifact.Artifacts, error) {
 		return nil, fmt.Errorf("sheets: couldn't read from spreadsheet: %w", err)
 
 	}
-	for i, row := range resp.Sheets[0].Data[0].RowData {
-
-		if i == 0 {
-			continue
-		}
-		if len(row.Values) < 7 {
-			continue
-		}
-
-		as = append(as, newArtifact(row))
 
+	if len(resp.Sheets) == 0 || len(resp.Sheets[0].Data) == 0 {
+		return nil, nil
 	}
 
-	return as, nil
-}
-
-func newArtifact(row *sheets.RowData) artifact.Artifact {
-	a := artifact.Artifact{}
-	a.Type = strings.ReplaceAll(extractString(*row.Values[0]), "\n", "")
-	a.Project = extractString(*row.Values[1])
-	a.Subproject = extractString(*row.Values[2])
-	a.Title = strings.ReplaceAll(extractString(*row.Values[3]), "\n", "")
-	a.Role = extractString(*row.Values[4])
-	a.ShippedDate = extractTime(*row.Values[5])
-	a.Link = extractString(*row.Values[6])
-	return a
+	return resp.Sheets[0].Data[0].RowData, nil
 }
 
 func extractString(val sheets.CellData) string {
@@ -417,35 +483,10 @@ func extractString(val sheets.CellData) string {
 	return ""
 }
 
-func extractTime(val sheets.CellData) time.Time {
-	sqlformat := "2006-01-02 15:04:05.999999-07"
-	otherformat := "01/02/2006"
-	if val.EffectiveValue != nil {
-		if val.EffectiveValue.NumberValue != nil {
-			d := int(*val.EffectiveValue.NumberValue) - 2
-			epochDate := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
-			result := epochDate.AddDate(0, 0, d)
-			return result
-		}
-
-		if val.EffectiveValue.StringValue != nil {
-			result, err := time.Parse(sqlformat, *val.EffectiveValue.StringValue)
-			if err == nil {
-				return result
-			}
-
-			result2, err := time.Parse(otherformat, *val.EffectiveValue.StringValue)
-			if err == nil {
-				return result2
-			}
-		}
-		tmp, err := val.EffectiveValue.MarshalJSON()
-		if err != nil {
-			log.Errorf("could not get json for: (%v) :%s", val.EffectiveValue, err)
-			return time.Time{}
-		}
-		log.Warnf("Was asked to translate a time and wasn't able to: %v", string(tmp))
+// extractText returns what a cell shows, whatever type of value it holds
+func extractText(val sheets.CellData) string {
+	if s := extractString(val); s != "" {
+		return s
 	}
-
-	return time.Time{}
+	return strings.TrimSpace(val.FormattedValue)
 }
@@ -1,21 +1,26 @@
 package gsheet
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tpryan/work/artifact"
+	"github.com/tpryan/work/gsheet/gsheettest"
 	"google.golang.org/api/sheets/v4"
 )
 
 func TestGsheetFormatSheet(t *testing.T) {
 	tests := map[string]struct {
-		in   int64
-		want []*sheets.Request
+		in      int64
+		columns int64
+		want    []*sheets.Request
 	}{
 		"basic": {
-			in: 1,
+			in:      1,
+			columns: 9,
 			want: []*sheets.Request{
 				{
 					UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
@@ -92,7 +97,7 @@ func TestGsheetFormatSheet(t *testing.T) {
 							SheetId:    1,
 							Dimension:  "COLUMNS",
 							StartIndex: 0,
-							EndIndex:   6,
+							EndIndex:   9,
 						},
 					},
 				},
@@ -103,7 +108,7 @@ func TestGsheetFormatSheet(t *testing.T) {
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			tmp := GSheet{}
-			got := tmp.FormatSheet(tc.in)
+			got := tmp.FormatSheet(tc.in, tc.columns)
 			assert.Equal(t, tc.want, got)
 		})
 	}
@@ -271,36 +276,250 @@ func TestGSheetextractString(t *testing.T) {
 	}
 }
 
-func TestGSheetextractTime(t *testing.T) {
-	tests := map[string]struct {
-		in    float64
-		want  time.Time
-		empty bool
-	}{
-		"basic": {
-			in:   45161,
-			want: time.Date(2023, 8, 23, 0, 0, 0, 0, time.UTC),
+func newTestGSheet(t *testing.T, tabs ...string) (*gsheettest.Server, GSheet) {
+	t.Helper()
+
+	srv := gsheettest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddSpreadsheet("test-spreadsheet", tabs...)
+
+	svc, err := srv.Service(context.Background())
+	if err != nil {
+		t.Fatalf("unable to create fake sheets service: %s", err)
+	}
+
+	return srv, New(NewService(svc), "test-spreadsheet")
+}
+
+func TestGSheetOfflineSheetID(t *testing.T) {
+	_, g := newTestGSheet(t, "Manual", "Other")
+
+	got, err := g.SheetID("Other")
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	assert.Equal(t, int64(2), got)
+
+	_, err = g.SheetID("Missing")
+	assert.Equal(t, errGSheetDoesNotExist, err)
+}
+
+func TestGSheetOfflineAddDelete(t *testing.T) {
+	srv, g := newTestGSheet(t, "Manual")
+
+	if err := g.Add("New"); err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	assert.Equal(t, []string{"Manual", "New"}, srv.Tabs("test-spreadsheet"))
+
+	assert.Equal(t, errGSheetAlreadyExists, g.Add("New"))
+
+	if err := g.Delete("New"); err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	assert.Equal(t, []string{"Manual"}, srv.Tabs("test-spreadsheet"))
+
+	assert.Equal(t, errGSheetDoesNotExist, g.Delete("New"))
+}
+
+func TestGSheetOfflineClear(t *testing.T) {
+	srv, g := newTestGSheet(t, "Manual")
+	// wider than A:Z, as sheets with attribute columns can be
+	wide := make([]string, 30)
+	wide[29] = "ad"
+	srv.SetValues("test-spreadsheet", "Manual", [][]string{{"a", "b"}, {"c", "d"}, wide})
+
+	if err := g.Clear("Manual"); err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	assert.Equal(t, [][]string{}, srv.Values("test-spreadsheet", "Manual"))
+
+	assert.Equal(t, errGSheetDoesNotExist, g.Clear("Missing"))
+}
+
+func TestGSheetOfflineToSheet(t *testing.T) {
+	arts := artifact.Artifacts{
+		artifact.Artifact{
+			Type:        "Pull Request",
+			Project:     "Proj",
+			Subproject:  "Sub",
+			Title:       "Title, with comma",
+			Role:        "author",
+			ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC),
+			Link:        "https://example.com/1",
+		},
+		artifact.Artifact{
+			Title:       "Unclassified",
+			ShippedDate: time.Date(2023, 8, 22, 0, 0, 0, 0, time.UTC),
+			Link:        "https://example.com/2",
 		},
+	}
 
-		"blank": {
-			want:  time.Time{},
-			empty: true,
+	tests := map[string]struct {
+		tabs  []string
+		start [][]string
+	}{
+		"newtab": {},
+		"existingtab": {
+			tabs:  []string{"Report"},
+			start: [][]string{{"old", "data"}, {"more", "old", "data"}, {"x"}, {"y"}},
 		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
+			srv, g := newTestGSheet(t, tc.tabs...)
+			if tc.start != nil {
+				srv.SetValues("test-spreadsheet", "Report", tc.start)
+			}
 
-			val := &sheets.CellData{EffectiveValue: &sheets.ExtendedValue{
-				NumberValue: &tc.in,
-			}}
+			if err := g.ToSheet("Report", arts); err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
 
-			if tc.empty {
-				val.EffectiveValue.NumberValue = nil
+			assert.Equal(t, [][]string{
+				{"Type", "Project", "Subproject", "Title", "Role", "Shipped Date", "Link"},
+				{"Pull Request", "Proj", "Sub", "Title, with comma", "author", "08/21/2023", `=HYPERLINK("https://example.com/1","https://example.com/1")`},
+				{"", "", "", "Unclassified", "", "08/22/2023", `=HYPERLINK("https://example.com/2","https://example.com/2")`},
+			}, srv.Values("test-spreadsheet", "Report"))
+
+			id, err := g.SheetID("Report")
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
 			}
+			reqs := srv.Requests("test-spreadsheet")
+			formatting := append(g.FormatSheet(id, int64(len(arts.Columns()))), g.FormatRows(id, arts)...)
+			assert.Equal(t, formatting, reqs[len(reqs)-len(formatting):])
 
-			got := extractTime(*val)
-			assert.Equal(t, tc.want, got)
+			got, err := g.Artifacts("Report")
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+			assert.Equal(t, arts, got)
+		})
+	}
+}
+
+func TestGSheetOfflineCustomColumns(t *testing.T) {
+	arts := artifact.Artifacts{
+		artifact.Artifact{
+			Type:        "Doc",
+			Title:       "Title",
+			ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC),
+			Link:        "https://example.com/1",
+			Extra:       "Extra",
+			Attributes:  map[string]string{"Impact": "High", "OKR": "2"},
+		},
+	}
+
+	srv, g := newTestGSheet(t)
+	if err := g.ToSheet("Report", arts); err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	assert.Equal(t, []string{"Type", "Project", "Subproject", "Title", "Role", "Shipped Date", "Link", "Extra", "Impact", "OKR"},
+		srv.Values("test-spreadsheet", "Report")[0])
+
+	got, err := g.Artifacts("Report")
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	assert.Equal(t, arts, got)
+
+	// columns are read by header, so they can be moved around
+	srv.SetValues("test-spreadsheet", "Report", [][]string{
+		{"Impact", "Link", "Title", "Shipped Date", "Type"},
+		{"High", `=HYPERLINK("https://example.com/1","https://example.com/1")`, "Title", "08/21/2023", "Doc"},
+	})
+
+	got, err = g.Artifacts("Report")
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	assert.Equal(t, artifact.Artifacts{{
+		Type:        "Doc",
+		Title:       "Title",
+		ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC),
+		Link:        "https://example.com/1",
+		Attributes:  map[string]string{"Impact": "High"},
+	}}, got)
+}
+
+func TestGSheetOfflineRead(t *testing.T) {
+	srv, g := newTestGSheet(t, "Report")
+	srv.SetValues("test-spreadsheet", "Report", [][]string{
+		{"Type", "Project", "Subproject", "Title", "Role", "Shipped Date", "Link"},
+		{"Design", "Manual", "", "Title", "", "08/21/2023", `=HYPERLINK("https://example.com/1","https://example.com/1")`},
+	})
+
+	got, err := g.Read(context.Background(), "Report")
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	assert.Equal(t, artifact.Artifacts{{
+		Type:        "Design",
+		Project:     "Manual",
+		Title:       "Title",
+		ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC),
+		Link:        "https://example.com/1",
+	}}, got)
+
+	got, err = g.Read(context.Background(), "Missing")
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+	assert.Equal(t, artifact.Artifacts{}, got)
+}
+
+// stubSpreadsheets fails every call with the same error
+type stubSpreadsheets struct {
+	err error
+}
+
+func (s stubSpreadsheets) Get(ctx context.Context, id string, ranges []string, includeGridData bool) (*sheets.Spreadsheet, error) {
+	return nil, s.err
+}
+
+func (s stubSpreadsheets) BatchUpdate(ctx context.Context, id string, req *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error) {
+	return nil, s.err
+}
+
+func (s stubSpreadsheets) UpdateValues(ctx context.Context, id, rng string, vr *sheets.ValueRange, inputOption string) (*sheets.UpdateValuesResponse, error) {
+	return nil, s.err
+}
+
+func (s stubSpreadsheets) ClearValues(ctx context.Context, id, rng string) (*sheets.ClearValuesResponse, error) {
+	return nil, s.err
+}
+
+func (s stubSpreadsheets) BatchUpdateValues(ctx context.Context, id string, req *sheets.BatchUpdateValuesRequest) (*sheets.BatchUpdateValuesResponse, error) {
+	return nil, s.err
+}
+
+func TestGSheetSpreadsheetsErrors(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want error
+	}{
+		"missing": {
+			err:  fmt.Errorf("googleapi: Error 400: Unable to parse range: Missing"),
+			want: errGSheetDoesNotExist,
+		},
+		"other": {
+			err:  fmt.Errorf("googleapi: Error 403: The caller does not have permission"),
+			want: fmt.Errorf("googleapi: Error 403: The caller does not have permission"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			g := New(stubSpreadsheets{err: tc.err}, "test-spreadsheet")
+
+			_, err := g.SheetID("Missing")
+			assert.Equal(t, tc.want, err)
+
+			assert.Equal(t, tc.want, g.Clear("Missing"))
 		})
 	}
 }
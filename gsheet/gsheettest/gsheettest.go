@@ -0,0 +1,518 @@
+// Package gsheettest provides an in-memory stand-in for the parts of the
+// Google Sheets v4 API that gsheet uses, so GSheet can be exercised without
+// credentials or network access.
+package gsheettest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Server is a fake Sheets API backed by memory
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	nextID       int64
+	spreadsheets map[string]*spreadsheet
+}
+
+type spreadsheet struct {
+	sheets   []*sheet
+	requests []*sheets.Request
+}
+
+type sheet struct {
+	id    int64
+	title string
+	cells [][]string
+}
+
+// NewServer starts a fake Sheets API. Callers should Close it when done.
+func NewServer() *Server {
+	s := &Server{spreadsheets: map[string]*spreadsheet{}, nextID: 1}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Service returns a Sheets client that talks to the fake server
+func (s *Server) Service(ctx context.Context) (*sheets.Service, error) {
+	return sheets.NewService(ctx,
+		option.WithEndpoint(s.URL+"/"),
+		option.WithHTTPClient(s.Client()),
+	)
+}
+
+// AddSpreadsheet creates an empty spreadsheet with the input tabs
+func (s *Server) AddSpreadsheet(id string, tabs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ss := &spreadsheet{}
+	for _, tab := range tabs {
+		ss.sheets = append(ss.sheets, &sheet{id: s.nextID, title: tab})
+		s.nextID++
+	}
+	s.spreadsheets[id] = ss
+}
+
+// SetValues replaces the contents of a tab with raw cell values, as if a
+// user had typed them in
+func (s *Server) SetValues(id, tab string, values [][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sh := s.sheet(id, tab); sh != nil {
+		sh.cells = values
+	}
+}
+
+// Values returns the raw cell values of a tab, trimmed of empty trailing
+// rows and cells
+func (s *Server) Values(id, tab string) [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sh := s.sheet(id, tab)
+	if sh == nil {
+		return nil
+	}
+
+	result := [][]string{}
+	for _, row := range sh.cells {
+		end := len(row)
+		for end > 0 && row[end-1] == "" {
+			end--
+		}
+		result = append(result, append([]string{}, row[:end]...))
+	}
+
+	for len(result) > 0 && len(result[len(result)-1]) == 0 {
+		result = result[:len(result)-1]
+	}
+
+	return result
+}
+
+// Tabs returns the titles of the tabs in a spreadsheet in order
+func (s *Server) Tabs(id string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []string{}
+	if ss, ok := s.spreadsheets[id]; ok {
+		for _, sh := range ss.sheets {
+			result = append(result, sh.title)
+		}
+	}
+	return result
+}
+
+// Requests returns every batch update request sent to a spreadsheet
+func (s *Server) Requests(id string) []*sheets.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ss, ok := s.spreadsheets[id]; ok {
+		return append([]*sheets.Request{}, ss.requests...)
+	}
+	return nil
+}
+
+func (s *Server) sheet(id, tab string) *sheet {
+	ss, ok := s.spreadsheets[id]
+	if !ok {
+		return nil
+	}
+
+	for _, sh := range ss.sheets {
+		if sh.title == tab {
+			return sh
+		}
+	}
+	return nil
+}
+
+type apiError struct {
+	code int
+	msg  string
+}
+
+func (e apiError) Error() string {
+	return e.msg
+}
+
+func errParseRange(rng string) error {
+	return apiError{http.StatusBadRequest, fmt.Sprintf("Unable to parse range: %s", rng)}
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp, err := s.route(r)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if ae, ok := err.(apiError); ok {
+			code = ae.code
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    code,
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) route(r *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(r.URL.Path, "/v4/spreadsheets/")
+	if path == r.URL.Path {
+		return nil, apiError{http.StatusNotFound, fmt.Sprintf("unknown path %s", r.URL.Path)}
+	}
+
+	id, rest, _ := strings.Cut(path, "/")
+	id, action, _ := strings.Cut(id, ":")
+
+	ss, ok := s.spreadsheets[id]
+	if !ok {
+		return nil, apiError{http.StatusNotFound, "Requested entity was not found."}
+	}
+
+	switch {
+	case rest == "" && action == "" && r.Method == http.MethodGet:
+		return s.get(ss, id, r)
+	case rest == "" && action == "batchUpdate" && r.Method == http.MethodPost:
+		req := &sheets.BatchUpdateSpreadsheetRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return nil, apiError{http.StatusBadRequest, err.Error()}
+		}
+		return s.batchUpdate(ss, id, req)
+	case rest == "values:batchUpdate" && r.Method == http.MethodPost:
+		req := &sheets.BatchUpdateValuesRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return nil, apiError{http.StatusBadRequest, err.Error()}
+		}
+		return s.batchUpdateValues(ss, id, req)
+	case strings.HasPrefix(rest, "values/") && strings.HasSuffix(rest, ":clear") && r.Method == http.MethodPost:
+		rng := strings.TrimSuffix(strings.TrimPrefix(rest, "values/"), ":clear")
+		return s.clear(ss, id, rng)
+	case strings.HasPrefix(rest, "values/") && r.Method == http.MethodPut:
+		rng := strings.TrimPrefix(rest, "values/")
+		vr := &sheets.ValueRange{}
+		if err := json.NewDecoder(r.Body).Decode(vr); err != nil {
+			return nil, apiError{http.StatusBadRequest, err.Error()}
+		}
+		return s.update(ss, id, rng, vr, r.URL.Query().Get("valueInputOption"))
+	}
+
+	return nil, apiError{http.StatusNotFound, fmt.Sprintf("unsupported call %s %s", r.Method, r.URL.Path)}
+}
+
+func (s *Server) get(ss *spreadsheet, id string, r *http.Request) (interface{}, error) {
+	grid := r.URL.Query().Get("includeGridData") == "true"
+	result := &sheets.Spreadsheet{SpreadsheetId: id}
+
+	targets := ss.sheets
+	if ranges := r.URL.Query()["ranges"]; len(ranges) > 0 {
+		targets = []*sheet{}
+		for _, rng := range ranges {
+			tab, _ := splitRange(rng)
+			sh := s.sheet(id, tab)
+			if sh == nil {
+				return nil, errParseRange(rng)
+			}
+			targets = append(targets, sh)
+		}
+	}
+
+	for i, sh := range targets {
+		out := &sheets.Sheet{
+			Properties: &sheets.SheetProperties{
+				SheetId: sh.id,
+				Title:   sh.title,
+				Index:   int64(i),
+			},
+		}
+
+		if grid {
+			data := &sheets.GridData{}
+			for _, row := range sh.cells {
+				rd := &sheets.RowData{}
+				for _, v := range row {
+					rd.Values = append(rd.Values, cellData(v))
+				}
+				data.RowData = append(data.RowData, rd)
+			}
+			out.Data = []*sheets.GridData{data}
+		}
+
+		result.Sheets = append(result.Sheets, out)
+	}
+
+	return result, nil
+}
+
+func (s *Server) batchUpdate(ss *spreadsheet, id string, req *sheets.BatchUpdateSpreadsheetRequest) (interface{}, error) {
+	resp := &sheets.BatchUpdateSpreadsheetResponse{SpreadsheetId: id}
+
+	for _, r := range req.Requests {
+		reply := &sheets.Response{}
+
+		switch {
+		case r.AddSheet != nil:
+			title := r.AddSheet.Properties.Title
+			if s.sheet(id, title) != nil {
+				return nil, apiError{http.StatusBadRequest, fmt.Sprintf(
+					"Invalid requests[0].addSheet: A sheet with the name \"%s\" already exists. Please enter another name.", title)}
+			}
+			sh := &sheet{id: s.nextID, title: title}
+			s.nextID++
+			ss.sheets = append(ss.sheets, sh)
+			reply.AddSheet = &sheets.AddSheetResponse{
+				Properties: &sheets.SheetProperties{SheetId: sh.id, Title: sh.title},
+			}
+		case r.DeleteSheet != nil:
+			found := false
+			for i, sh := range ss.sheets {
+				if sh.id == r.DeleteSheet.SheetId {
+					ss.sheets = append(ss.sheets[:i], ss.sheets[i+1:]...)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, apiError{http.StatusBadRequest, fmt.Sprintf(
+					"Invalid requests[0].deleteSheet: No sheet with id: %d", r.DeleteSheet.SheetId)}
+			}
+		case r.DeleteDimension != nil:
+			if err := s.deleteDimension(ss, r.DeleteDimension.Range); err != nil {
+				return nil, err
+			}
+		case r.UpdateSheetProperties != nil, r.RepeatCell != nil, r.AutoResizeDimensions != nil:
+			// formatting has no effect on values
+		default:
+			return nil, apiError{http.StatusBadRequest, "gsheettest: unsupported batch request"}
+		}
+
+		ss.requests = append(ss.requests, r)
+		resp.Replies = append(resp.Replies, reply)
+	}
+
+	return resp, nil
+}
+
+func (s *Server) deleteDimension(ss *spreadsheet, rng *sheets.DimensionRange) error {
+	var sh *sheet
+	for _, candidate := range ss.sheets {
+		if candidate.id == rng.SheetId {
+			sh = candidate
+		}
+	}
+	if sh == nil {
+		return apiError{http.StatusBadRequest, fmt.Sprintf(
+			"Invalid requests[0].deleteDimension: No grid with id: %d", rng.SheetId)}
+	}
+
+	if rng.Dimension != "ROWS" {
+		return apiError{http.StatusBadRequest, "gsheettest: only row deletion is supported"}
+	}
+
+	start, end := int(rng.StartIndex), int(rng.EndIndex)
+	if start >= len(sh.cells) {
+		return nil
+	}
+	if end > len(sh.cells) {
+		end = len(sh.cells)
+	}
+	sh.cells = append(sh.cells[:start], sh.cells[end:]...)
+
+	return nil
+}
+
+func (s *Server) batchUpdateValues(ss *spreadsheet, id string, req *sheets.BatchUpdateValuesRequest) (interface{}, error) {
+	resp := &sheets.BatchUpdateValuesResponse{SpreadsheetId: id}
+
+	for _, vr := range req.Data {
+		if _, err := s.update(ss, id, vr.Range, vr, req.ValueInputOption); err != nil {
+			return nil, err
+		}
+		resp.TotalUpdatedRows += int64(len(vr.Values))
+	}
+
+	return resp, nil
+}
+
+func (s *Server) clear(ss *spreadsheet, id, rng string) (interface{}, error) {
+	tab, a1 := splitRange(rng)
+	sh := s.sheet(id, tab)
+	if sh == nil {
+		return nil, errParseRange(rng)
+	}
+
+	startCol, startRow, endCol, endRow := bounds(a1)
+	for r := range sh.cells {
+		if r < startRow || (endRow >= 0 && r > endRow) {
+			continue
+		}
+		for c := range sh.cells[r] {
+			if c < startCol || (endCol >= 0 && c > endCol) {
+				continue
+			}
+			sh.cells[r][c] = ""
+		}
+	}
+
+	return &sheets.ClearValuesResponse{SpreadsheetId: id, ClearedRange: rng}, nil
+}
+
+func (s *Server) update(ss *spreadsheet, id, rng string, vr *sheets.ValueRange, input string) (interface{}, error) {
+	if input != "RAW" && input != "USER_ENTERED" {
+		return nil, apiError{http.StatusBadRequest, "Invalid valueInputOption"}
+	}
+
+	tab, a1 := splitRange(rng)
+	sh := s.sheet(id, tab)
+	if sh == nil {
+		return nil, errParseRange(rng)
+	}
+
+	// values are always interpreted the way USER_ENTERED would, when read
+	startCol, startRow, _, _ := bounds(a1)
+	for i, row := range vr.Values {
+		for j, v := range row {
+			r, c := startRow+i, startCol+j
+			for len(sh.cells) <= r {
+				sh.cells = append(sh.cells, []string{})
+			}
+			for len(sh.cells[r]) <= c {
+				sh.cells[r] = append(sh.cells[r], "")
+			}
+			sh.cells[r][c] = fmt.Sprint(v)
+		}
+	}
+
+	return &sheets.UpdateValuesResponse{
+		SpreadsheetId: id,
+		UpdatedRange:  rng,
+		UpdatedRows:   int64(len(vr.Values)),
+	}, nil
+}
+
+// splitRange separates a range like 'Name'!A1:B2 into its tab and cells
+func splitRange(rng string) (string, string) {
+	tab, cells := rng, ""
+	if i := strings.LastIndex(rng, "!"); i >= 0 {
+		tab, cells = rng[:i], rng[i+1:]
+	}
+
+	if len(tab) > 1 && strings.HasPrefix(tab, "'") && strings.HasSuffix(tab, "'") {
+		tab = strings.ReplaceAll(tab[1:len(tab)-1], "''", "'")
+	}
+
+	return tab, cells
+}
+
+var cellRef = regexp.MustCompile(`^([A-Za-z]*)(\d*)$`)
+
+// bounds returns zero based start and end indexes for an A1 range. Missing
+// ends are reported as -1.
+func bounds(a1 string) (int, int, int, int) {
+	if a1 == "" {
+		return 0, 0, -1, -1
+	}
+
+	start, end, hasEnd := strings.Cut(a1, ":")
+	startCol, startRow := cell(start)
+	if startCol < 0 {
+		startCol = 0
+	}
+	if startRow < 0 {
+		startRow = 0
+	}
+
+	if !hasEnd {
+		return startCol, startRow, startCol, startRow
+	}
+
+	endCol, endRow := cell(end)
+	return startCol, startRow, endCol, endRow
+}
+
+func cell(ref string) (int, int) {
+	m := cellRef.FindStringSubmatch(ref)
+	if m == nil {
+		return -1, -1
+	}
+
+	col := -1
+	if m[1] != "" {
+		col = 0
+		for _, ch := range strings.ToUpper(m[1]) {
+			col = col*26 + int(ch-'A'+1)
+		}
+		col--
+	}
+
+	row := -1
+	if m[2] != "" {
+		n, _ := strconv.Atoi(m[2])
+		row = n - 1
+	}
+
+	return col, row
+}
+
+var hyperlink = regexp.MustCompile(`^=HYPERLINK\("((?:[^"]|"")*)",\s*"((?:[^"]|"")*)"\)$`)
+
+// epoch is day zero for spreadsheet date serial numbers
+var epoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// cellData works out what Sheets would show for a value entered by a user
+func cellData(v string) *sheets.CellData {
+	if v == "" {
+		return &sheets.CellData{}
+	}
+
+	entered := v
+	cd := &sheets.CellData{
+		UserEnteredValue: &sheets.ExtendedValue{StringValue: &entered},
+		FormattedValue:   v,
+	}
+
+	if m := hyperlink.FindStringSubmatch(v); m != nil {
+		title := strings.ReplaceAll(m[2], `""`, `"`)
+		cd.UserEnteredValue = &sheets.ExtendedValue{FormulaValue: &entered}
+		cd.EffectiveValue = &sheets.ExtendedValue{StringValue: &title}
+		cd.FormattedValue = title
+		cd.Hyperlink = strings.ReplaceAll(m[1], `""`, `"`)
+		return cd
+	}
+
+	if t, err := time.Parse("01/02/2006", v); err == nil {
+		serial := float64(t.Sub(epoch) / (24 * time.Hour))
+		cd.EffectiveValue = &sheets.ExtendedValue{NumberValue: &serial}
+		return cd
+	}
+
+	cd.EffectiveValue = &sheets.ExtendedValue{StringValue: &entered}
+	return cd
+}
@@ -0,0 +1,60 @@
+package gsheettest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitRange(t *testing.T) {
+	tests := map[string]struct {
+		in        string
+		wantTab   string
+		wantCells string
+	}{
+		"tab":    {in: "Manual", wantTab: "Manual"},
+		"cells":  {in: "Manual!A1:Z100000", wantTab: "Manual", wantCells: "A1:Z100000"},
+		"quoted": {in: "'Source - Github'!A:Z", wantTab: "Source - Github", wantCells: "A:Z"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tab, cells := splitRange(tc.in)
+			assert.Equal(t, tc.wantTab, tab)
+			assert.Equal(t, tc.wantCells, cells)
+		})
+	}
+}
+
+func TestBounds(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want [4]int
+	}{
+		"all":     {in: "", want: [4]int{0, 0, -1, -1}},
+		"columns": {in: "A:Z", want: [4]int{0, 0, 25, -1}},
+		"cells":   {in: "B2:AA10", want: [4]int{1, 1, 26, 9}},
+		"single":  {in: "C3", want: [4]int{2, 2, 2, 2}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sc, sr, ec, er := bounds(tc.in)
+			assert.Equal(t, tc.want, [4]int{sc, sr, ec, er})
+		})
+	}
+}
+
+func TestCellData(t *testing.T) {
+	link := cellData(`=HYPERLINK("http://example.com/edit","http://example.com")`)
+	assert.Equal(t, "http://example.com", *link.EffectiveValue.StringValue)
+	assert.Equal(t, "http://example.com/edit", link.Hyperlink)
+
+	date := cellData("08/23/2023")
+	assert.Equal(t, float64(45161), *date.EffectiveValue.NumberValue)
+
+	text := cellData("text")
+	assert.Equal(t, "text", *text.EffectiveValue.StringValue)
+
+	assert.Nil(t, cellData("").EffectiveValue)
+}
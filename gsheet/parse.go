@@ -0,0 +1,188 @@
+package gsheet
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tpryan/work/artifact"
+	"google.golang.org/api/sheets/v4"
+)
+
+// RowError describes a row of a sheet that couldn't be read as an artifact
+type RowError struct {
+	Sheet string
+	Row   int
+	Err   error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("%s row %d: %s", e.Sheet, e.Row, e.Err)
+}
+
+// Parse reads the named sheet as artifacts, matching columns by their
+// header. Aliases map the headers used by a sheet to artifact columns, for
+// sheets exported by tools that name their columns differently. Rows that
+// can't be read are returned as problems rather than artifacts.
+func (g *GSheet) Parse(name string, aliases map[string]string) (artifact.Artifacts, []RowError, error) {
+	as := artifact.Artifacts{}
+	problems := []RowError{}
+
+	rows, err := g.rows(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := header(rows, aliases)
+
+	for i, row := range rows {
+		if i == 0 || !hasValues(row) {
+			continue
+		}
+
+		art, err := parseArtifact(columns, row)
+		if err != nil {
+			problems = append(problems, RowError{Sheet: name, Row: i + 1, Err: err})
+			continue
+		}
+
+		as = append(as, art)
+	}
+
+	return as, problems, nil
+}
+
+// header returns the column names in the first row of a sheet, with any
+// aliases resolved. Sheets whose first row doesn't name the link column are
+// read in the standard order.
+func header(rows []*sheets.RowData, aliases map[string]string) []string {
+	if len(rows) == 0 {
+		return artifact.Header
+	}
+
+	columns := []string{}
+	for _, v := range rows[0].Values {
+		columns = append(columns, alias(extractText(*v), aliases))
+	}
+
+	if linkColumn(columns) < 0 {
+		return artifact.Header
+	}
+
+	return columns
+}
+
+// alias returns the artifact column a sheet header has been mapped to, or
+// the header itself if it hasn't been
+func alias(h string, aliases map[string]string) string {
+	for k, v := range aliases {
+		if strings.EqualFold(strings.TrimSpace(k), strings.TrimSpace(h)) {
+			return v
+		}
+	}
+	return h
+}
+
+func linkColumn(columns []string) int {
+	for i, c := range columns {
+		if strings.EqualFold(strings.TrimSpace(c), "Link") {
+			return i
+		}
+	}
+	return -1
+}
+
+// isArtifact reports whether a row reaches as far as the link column; rows
+// that stop short of it aren't artifacts
+func isArtifact(columns []string, row *sheets.RowData) bool {
+	return len(row.Values) > linkColumn(columns)
+}
+
+// parseArtifact reads a row as an artifact, failing if it has no link or a
+// shipped date that isn't a date
+func parseArtifact(columns []string, row *sheets.RowData) (artifact.Artifact, error) {
+	if !isArtifact(columns, row) {
+		return artifact.Artifact{}, fmt.Errorf("no link")
+	}
+
+	a, err := newArtifact(columns, row)
+	if err != nil {
+		return a, err
+	}
+	if a.Link == "" {
+		return a, fmt.Errorf("no link")
+	}
+
+	return a, nil
+}
+
+// newArtifact reads whatever it can from a row. An error is returned for the
+// first cell that can't be read, such as a shipped date that isn't a date,
+// and that cell is left empty.
+func newArtifact(columns []string, row *sheets.RowData) (artifact.Artifact, error) {
+	a := artifact.Artifact{}
+	var result error
+
+	for i, v := range row.Values {
+		if i >= len(columns) || v == nil {
+			break
+		}
+
+		var err error
+		switch strings.ToLower(strings.TrimSpace(columns[i])) {
+		case "type", "title":
+			err = a.Set(columns[i], strings.ReplaceAll(extractString(*v), "\n", ""))
+		case "project", "subproject", "role", "link":
+			err = a.Set(columns[i], extractString(*v))
+		case "shipped date":
+			a.ShippedDate, err = parseTime(*v)
+		default:
+			err = a.Set(columns[i], extractText(*v))
+		}
+
+		if err != nil && result == nil {
+			result = fmt.Errorf("%s: %w", strings.TrimSpace(columns[i]), err)
+		}
+	}
+
+	return a, result
+}
+
+// parseTime reads a date from a cell. Empty cells are the zero time.
+func parseTime(val sheets.CellData) (time.Time, error) {
+	sqlformat := "2006-01-02 15:04:05.999999-07"
+	otherformat := "01/02/2006"
+
+	if val.EffectiveValue == nil {
+		return time.Time{}, nil
+	}
+
+	if val.EffectiveValue.NumberValue != nil {
+		d := int(*val.EffectiveValue.NumberValue) - 2
+		epochDate := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+		return epochDate.AddDate(0, 0, d), nil
+	}
+
+	if val.EffectiveValue.StringValue != nil {
+		s := strings.TrimSpace(*val.EffectiveValue.StringValue)
+		if s == "" {
+			return time.Time{}, nil
+		}
+
+		if result, err := time.Parse(sqlformat, s); err == nil {
+			return result, nil
+		}
+
+		if result, err := time.Parse(otherformat, s); err == nil {
+			return result, nil
+		}
+
+		return time.Time{}, fmt.Errorf("could not parse shipped date %q", s)
+	}
+
+	tmp, err := val.EffectiveValue.MarshalJSON()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not read shipped date: %s", err)
+	}
+	return time.Time{}, fmt.Errorf("could not parse shipped date %s", string(tmp))
+}
@@ -0,0 +1,122 @@
+package gsheet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tpryan/work/artifact"
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestGSheetParse(t *testing.T) {
+	tests := map[string]struct {
+		values       [][]string
+		aliases      map[string]string
+		want         artifact.Artifacts
+		wantProblems []string
+	}{
+		"standard": {
+			values: [][]string{
+				{"Type", "Project", "Subproject", "Title", "Role", "Shipped Date", "Link"},
+				{"CL", "Proj", "", "Title", "author", "08/21/2023", "http://cl/1"},
+			},
+			want: artifact.Artifacts{
+				{Type: "CL", Project: "Proj", Title: "Title", Role: "author", ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC), Link: "http://cl/1"},
+			},
+		},
+		"aliases": {
+			values: [][]string{
+				{"CL", "Description", "Submitted"},
+				{"http://cl/1", "Title", "08/21/2023"},
+			},
+			aliases: map[string]string{"cl": "Link", "Description": "Title", "Submitted": "Shipped Date"},
+			want: artifact.Artifacts{
+				{Title: "Title", ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC), Link: "http://cl/1"},
+			},
+		},
+		"problems": {
+			values: [][]string{
+				{"Title", "Shipped Date", "Link"},
+				{"No link"},
+				{},
+				{"Bad date", "someday", "http://cl/2"},
+				{"Good", "08/21/2023", "http://cl/3"},
+			},
+			want: artifact.Artifacts{
+				{Title: "Good", ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC), Link: "http://cl/3"},
+			},
+			wantProblems: []string{
+				"Source row 2: no link",
+				"Source row 4: Shipped Date: could not parse shipped date \"someday\"",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv, g := newTestGSheet(t, "Source")
+			srv.SetValues("test-spreadsheet", "Source", tc.values)
+
+			got, problems, err := g.Parse("Source", tc.aliases)
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			var gotProblems []string
+			for _, p := range problems {
+				gotProblems = append(gotProblems, p.Error())
+			}
+
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantProblems, gotProblems)
+		})
+	}
+}
+
+func TestGSheetParseTime(t *testing.T) {
+	tests := map[string]struct {
+		number *float64
+		text   *string
+		want   time.Time
+		errStr string
+	}{
+		"serial": {
+			number: func() *float64 { f := 45161.0; return &f }(),
+			want:   time.Date(2023, 8, 23, 0, 0, 0, 0, time.UTC),
+		},
+		"text": {
+			text: func() *string { s := "08/21/2023"; return &s }(),
+			want: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC),
+		},
+		"blank": {
+			want: time.Time{},
+		},
+		"bad": {
+			text:   func() *string { s := "someday"; return &s }(),
+			errStr: "could not parse shipped date \"someday\"",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			val := sheets.CellData{}
+			if tc.number != nil || tc.text != nil {
+				val.EffectiveValue = &sheets.ExtendedValue{
+					NumberValue: tc.number,
+					StringValue: tc.text,
+				}
+			}
+
+			got, err := parseTime(val)
+			if tc.errStr != "" {
+				assert.EqualError(t, err, tc.errStr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
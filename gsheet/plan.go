@@ -0,0 +1,143 @@
+package gsheet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tpryan/work/artifact"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Plan describes the changes ToSheet would make to a sheet, without making
+// them
+type Plan struct {
+	Sheet    string
+	Exists   bool
+	Rows     int
+	Added    artifact.Artifacts
+	Removed  artifact.Artifacts
+	Changed  artifact.Artifacts
+	Requests []*sheets.Request
+}
+
+// Plan works out what writing the input artifacts to the named sheet would
+// change, comparing them against what is in the sheet now
+func (g *GSheet) Plan(name string, a artifact.Artifacts) (Plan, error) {
+	p := Plan{Sheet: name, Rows: len(a)}
+	current := artifact.Artifacts{}
+
+	id, err := g.SheetID(name)
+	if err != nil && err != errGSheetDoesNotExist {
+		return p, fmt.Errorf("sheets: failed to look up sheet %s", err)
+	}
+
+	if err == nil {
+		p.Exists = true
+		current, err = g.Artifacts(name)
+		if err != nil {
+			return p, fmt.Errorf("sheets: failed to read sheet %s", err)
+		}
+	}
+
+	// rows are paired the same way Sync pairs them, so the plan can't
+	// disagree with what gets written
+	rows := map[int]artifact.Artifact{}
+	for i, art := range current {
+		rows[i] = art
+	}
+	matches, claimed := pair(rows, a)
+
+	for j, art := range a {
+		if !claimed[j] {
+			p.Added = append(p.Added, art)
+		}
+	}
+
+	for i, art := range current {
+		if _, ok := matches[i]; !ok {
+			p.Removed = append(p.Removed, art)
+		}
+	}
+
+	p.Requests = g.FormatRequests(id, a)
+
+	return p, nil
+}
+
+// DryRun returns a description of what Write would do to the named sheet
+func (g *GSheet) DryRun(ctx context.Context, name string, a artifact.Artifacts) (string, error) {
+	p, err := g.Plan(name, a)
+	if err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
+// String returns a human readable summary of the plan
+func (p Plan) String() string {
+	sb := strings.Builder{}
+
+	state := "existing sheet"
+	if !p.Exists {
+		state = "new sheet"
+	}
+
+	sb.WriteString(fmt.Sprintf("%s (%s): %d rows would be written\n", p.Sheet, state, p.Rows))
+
+	sb.WriteString(fmt.Sprintf("  %d new\n", len(p.Added)))
+	for _, art := range p.Added {
+		sb.WriteString(fmt.Sprintf("    + %s %s\n", art.Title, art.Link))
+	}
+
+	sb.WriteString(fmt.Sprintf("  %d removed\n", len(p.Removed)))
+	for _, art := range p.Removed {
+		sb.WriteString(fmt.Sprintf("    - %s %s\n", art.Title, art.Link))
+	}
+
+	if len(p.Changed) > 0 {
+		sb.WriteString(fmt.Sprintf("  %d changed\n", len(p.Changed)))
+		for _, art := range p.Changed {
+			sb.WriteString(fmt.Sprintf("    ~ %s %s\n", art.Title, art.Link))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("  %d formatting requests\n", len(p.Requests)))
+	for _, req := range p.Requests {
+		sb.WriteString(fmt.Sprintf("    %s\n", describe(req)))
+	}
+
+	return sb.String()
+}
+
+// describe summarizes a batch request in a single line
+func describe(req *sheets.Request) string {
+	switch {
+	case req.UpdateSheetProperties != nil:
+		return fmt.Sprintf("updateSheetProperties %s", req.UpdateSheetProperties.Fields)
+	case req.RepeatCell != nil:
+		return fmt.Sprintf("repeatCell %s %s", req.RepeatCell.Fields, describeRange(req.RepeatCell.Range))
+	case req.DeleteDimension != nil:
+		d := req.DeleteDimension.Range
+		return fmt.Sprintf("deleteDimension %s %d-%d", d.Dimension, d.StartIndex+1, d.EndIndex)
+	case req.AutoResizeDimensions != nil:
+		d := req.AutoResizeDimensions.Dimensions
+		return fmt.Sprintf("autoResizeDimensions %s %d-%d", d.Dimension, d.StartIndex, d.EndIndex)
+	}
+	return "other"
+}
+
+func describeRange(r *sheets.GridRange) string {
+	if r == nil {
+		return ""
+	}
+
+	rows := "all rows"
+	if r.EndRowIndex > 0 {
+		rows = fmt.Sprintf("rows %d-%d", r.StartRowIndex+1, r.EndRowIndex)
+	} else if r.StartRowIndex > 0 {
+		rows = fmt.Sprintf("rows %d-", r.StartRowIndex+1)
+	}
+
+	return rows
+}
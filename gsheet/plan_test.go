@@ -0,0 +1,125 @@
+package gsheet
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tpryan/work/artifact"
+)
+
+func TestGSheetPlan(t *testing.T) {
+	keep := artifact.Artifact{
+		Type:        "Doc",
+		Project:     "Proj",
+		Subproject:  "Sub",
+		Title:       "Kept",
+		ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC),
+		Link:        "https://docs.google.com/document/d/1234/edit?usp=drivesdk",
+	}
+	gone := artifact.Artifact{
+		Type:        "Doc",
+		Project:     "Proj",
+		Subproject:  "Sub",
+		Title:       "Gone",
+		ShippedDate: time.Date(2023, 8, 20, 0, 0, 0, 0, time.UTC),
+		Link:        "https://example.com/gone",
+	}
+	fresh := artifact.Artifact{
+		Title:       "Fresh",
+		ShippedDate: time.Date(2023, 8, 22, 0, 0, 0, 0, time.UTC),
+		Link:        "https://example.com/fresh",
+	}
+
+	pull1 := artifact.Artifact{Title: "One", Link: "https://github.com/o/r/pull/1"}
+	pull12 := artifact.Artifact{Title: "Twelve", Link: "https://github.com/o/r/pull/12"}
+
+	tests := map[string]struct {
+		existing     artifact.Artifacts
+		in           artifact.Artifacts
+		wantExists   bool
+		wantAdded    []string
+		wantRemoved  []string
+		wantRequests int
+	}{
+		"newsheet": {
+			in:           artifact.Artifacts{keep, fresh},
+			wantAdded:    []string{"Kept", "Fresh"},
+			wantRequests: 9,
+		},
+		"existing": {
+			existing:     artifact.Artifacts{gone, keep},
+			in:           artifact.Artifacts{keep, fresh},
+			wantExists:   true,
+			wantAdded:    []string{"Fresh"},
+			wantRemoved:  []string{"Gone"},
+			wantRequests: 9,
+		},
+		"prefixlinks": {
+			existing:     artifact.Artifacts{pull12},
+			in:           artifact.Artifacts{pull1},
+			wantExists:   true,
+			wantAdded:    []string{"One"},
+			wantRemoved:  []string{"Twelve"},
+			wantRequests: 9,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv, g := newTestGSheet(t)
+			if tc.existing != nil {
+				if err := g.ToSheet("Report", tc.existing); err != nil {
+					t.Fatalf("could not set up sheet: %s", err)
+				}
+			}
+			before := srv.Values("test-spreadsheet", "Report")
+			requests := len(srv.Requests("test-spreadsheet"))
+
+			got, err := g.Plan("Report", tc.in)
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.Equal(t, tc.wantExists, got.Exists)
+			assert.Equal(t, len(tc.in), got.Rows)
+			assert.Equal(t, tc.wantAdded, titles(got.Added))
+			assert.Equal(t, tc.wantRemoved, titles(got.Removed))
+			assert.Equal(t, tc.wantRequests, len(got.Requests))
+
+			assert.Equal(t, before, srv.Values("test-spreadsheet", "Report"), "plan should not change values")
+			assert.Equal(t, requests, len(srv.Requests("test-spreadsheet")), "plan should not send batch updates")
+		})
+	}
+}
+
+func TestGSheetDryRun(t *testing.T) {
+	_, g := newTestGSheet(t)
+
+	got, err := g.DryRun(context.Background(), "Report", artifact.Artifacts{
+		{Title: "Fresh", Link: "https://example.com/fresh"},
+	})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	for _, want := range []string{
+		"Report (new sheet): 1 rows would be written",
+		"1 new",
+		"+ Fresh https://example.com/fresh",
+		"0 removed",
+		"repeatCell userEnteredFormat.backgroundColorStyle rows 2-2",
+	} {
+		assert.True(t, strings.Contains(got, want), "expected %q in:\n%s", want, got)
+	}
+}
+
+func titles(a artifact.Artifacts) []string {
+	var result []string
+	for _, art := range a {
+		result = append(result, art.Title)
+	}
+	return result
+}
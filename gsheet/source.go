@@ -0,0 +1,43 @@
+package gsheet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/tpryan/work"
+	"github.com/tpryan/work/artifact"
+)
+
+// Source reads artifacts that were already recorded in a sheet
+type Source struct {
+	name    string
+	aliases map[string]string
+	sheet   *GSheet
+}
+
+// NewSource returns a source that reads the sheet named in the input
+// configuration
+func NewSource(g *GSheet, cfg work.SourceConfig) *Source {
+	return &Source{name: cfg.Name, aliases: cfg.Columns, sheet: g}
+}
+
+// Name returns the name of the source
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Collect returns the contents of the sheet as artifacts. Rows that can't be
+// read are logged with their row number.
+func (s *Source) Collect(ctx context.Context, criteria work.Criteria) (artifact.Artifacts, error) {
+	arts, problems, err := s.sheet.Parse(s.name, s.aliases)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read sheet %s: %w", s.name, err)
+	}
+
+	for _, p := range problems {
+		log.Warnf("sheets: skipping %s", p)
+	}
+
+	return arts, nil
+}
@@ -0,0 +1,338 @@
+package gsheet
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/tpryan/work/artifact"
+	"google.golang.org/api/sheets/v4"
+)
+
+// changes is the difference between what is in a sheet and a set of
+// artifacts, expressed as the edits needed to bring the sheet up to date.
+// The artifacts occupy the first of the columns; anything to the right of
+// them belongs to the people using the sheet.
+type changes struct {
+	id      int64
+	exists  bool
+	columns []string
+	added   artifact.Artifacts
+	removed artifact.Artifacts
+	changed artifact.Artifacts
+	values  []*sheets.ValueRange
+	deletes []int64
+	placed  []placement
+}
+
+// placement is an artifact and the row it sits in once the update is done
+type placement struct {
+	row int64
+	art artifact.Artifact
+}
+
+// diff matches the rows in the named sheet against the input artifacts by
+// link and works out which rows need to be appended, rewritten or deleted
+func (g *GSheet) diff(name string, a artifact.Artifacts) (changes, error) {
+	c := changes{}
+
+	id, err := g.SheetID(name)
+	if err != nil && err != errGSheetDoesNotExist {
+		return c, fmt.Errorf("sheets: failed to look up sheet %s", err)
+	}
+	if err == errGSheetDoesNotExist {
+		c.added = a
+		return c, nil
+	}
+	c.id = id
+	c.exists = true
+	c.columns = a.Columns()
+
+	rows, err := g.rows(name)
+	if err != nil {
+		return c, fmt.Errorf("sheets: failed to read sheet %s", err)
+	}
+
+	c.values = append(c.values, &sheets.ValueRange{
+		Range:  span(name, 0, len(c.columns)),
+		Values: [][]interface{}{a.ToInterfaces()[0]},
+	})
+
+	columns := header(rows, nil)
+	last := 0
+
+	// rows without a link were not written by us, so they are left alone
+	current := map[int]artifact.Artifact{}
+	for i, row := range rows {
+		if hasValues(row) {
+			last = i
+		}
+		if i == 0 || !isArtifact(columns, row) {
+			continue
+		}
+		cur, _ := newArtifact(columns, row)
+		if cur.Link != "" {
+			current[i] = cur
+		}
+	}
+
+	matches, claimed := pair(current, a)
+
+	for i := range rows {
+		cur, ok := current[i]
+		if !ok {
+			continue
+		}
+
+		match, ok := matches[i]
+		if !ok {
+			c.removed = append(c.removed, cur)
+			c.deletes = append(c.deletes, int64(i))
+			continue
+		}
+
+		art := a[match]
+		// rows move up by one for every row deleted above them
+		c.placed = append(c.placed, placement{row: int64(i - len(c.deletes)), art: art})
+
+		if !same(cur, art, c.columns) {
+			c.changed = append(c.changed, art)
+			c.values = append(c.values, c.rowRange(name, i, art))
+		}
+	}
+
+	next := last + 1
+	for j, art := range a {
+		if claimed[j] {
+			continue
+		}
+		c.added = append(c.added, art)
+		c.placed = append(c.placed, placement{row: int64(next - len(c.deletes)), art: art})
+		c.values = append(c.values, c.rowRange(name, next, art))
+		next++
+	}
+
+	return c, nil
+}
+
+// pair matches sheet rows, keyed by their index, to the artifacts that
+// belong in them. Exact link matches are made first, across every row, so
+// that a link that merely contains another, like .../pull/12 and
+// .../pull/1, can't take its row. Only then are the rows left over matched
+// more loosely, to allow for shortened links. It returns the artifact for
+// each matched row and which artifacts were matched.
+func pair(current map[int]artifact.Artifact, a artifact.Artifacts) (map[int]int, []bool) {
+	matches := map[int]int{}
+	claimed := make([]bool, len(a))
+
+	rows := []int{}
+	for i := range current {
+		rows = append(rows, i)
+	}
+	sort.Ints(rows)
+
+	passes := []func(art artifact.Artifact, link string) bool{
+		func(art artifact.Artifact, link string) bool { return art.SameLink(link) },
+		func(art artifact.Artifact, link string) bool { return art.Matches(link) },
+	}
+
+	for _, match := range passes {
+		for _, i := range rows {
+			cur := current[i]
+			if _, ok := matches[i]; ok {
+				continue
+			}
+
+			for j, art := range a {
+				if !claimed[j] && match(art, cur.Link) {
+					matches[i] = j
+					claimed[j] = true
+					break
+				}
+			}
+		}
+	}
+
+	return matches, claimed
+}
+
+// requests returns the structural and formatting batch requests needed to
+// finish an incremental update once the values have been written
+func (g *GSheet) requests(c changes) []*sheets.Request {
+	requests := []*sheets.Request{}
+
+	// delete from the bottom up so earlier deletions don't move later rows
+	deletes := append([]int64{}, c.deletes...)
+	sort.Slice(deletes, func(i, j int) bool { return deletes[i] > deletes[j] })
+
+	for _, row := range deletes {
+		requests = append(requests, &sheets.Request{
+			DeleteDimension: &sheets.DeleteDimensionRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:    c.id,
+					Dimension:  "ROWS",
+					StartIndex: row,
+					EndIndex:   row + 1,
+				},
+			},
+		})
+	}
+
+	columns := int64(len(c.columns))
+	requests = append(requests, &sheets.Request{
+		RepeatCell: &sheets.RepeatCellRequest{
+			Fields: "userEnteredFormat",
+			Range: &sheets.GridRange{
+				SheetId:        c.id,
+				EndColumnIndex: columns,
+			},
+		},
+	})
+	requests = append(requests, g.FormatSheet(c.id, columns)...)
+	for _, p := range c.placed {
+		requests = append(requests, formatRow(c.id, p.row, p.art)...)
+	}
+
+	return requests
+}
+
+// Sync brings the named sheet up to date with the input artifacts without
+// clearing it. Rows are matched by link; new artifacts are appended, changed
+// ones are rewritten in place, and rows whose artifacts are gone are deleted.
+// Columns to the right of the artifact columns are left alone.
+func (g *GSheet) Sync(name string, a artifact.Artifacts) error {
+	c, err := g.diff(name, a)
+	if err != nil {
+		return err
+	}
+
+	if !c.exists {
+		return g.ToSheet(name, a)
+	}
+
+	req := &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "USER_ENTERED",
+		Data:             c.values,
+	}
+	if _, err := g.svc.BatchUpdateValues(context.Background(), g.id, req); err != nil {
+		return fmt.Errorf("sheets: failed to update rows in sheet: %s", err)
+	}
+
+	batchreq := &sheets.BatchUpdateSpreadsheetRequest{Requests: g.requests(c)}
+	if _, err := g.svc.BatchUpdate(context.Background(), g.id, batchreq); err != nil {
+		return fmt.Errorf("sheets: failed to apply row changes %s", err)
+	}
+
+	return nil
+}
+
+// PlanSync works out what Sync would change in the named sheet
+func (g *GSheet) PlanSync(name string, a artifact.Artifacts) (Plan, error) {
+	c, err := g.diff(name, a)
+	if err != nil {
+		return Plan{Sheet: name}, err
+	}
+
+	p := Plan{
+		Sheet:   name,
+		Exists:  c.exists,
+		Rows:    len(c.added) + len(c.changed),
+		Added:   c.added,
+		Removed: c.removed,
+		Changed: c.changed,
+	}
+
+	if !c.exists {
+		p.Rows = len(a)
+		p.Requests = g.FormatRequests(0, a)
+		return p, nil
+	}
+
+	p.Requests = g.requests(c)
+	return p, nil
+}
+
+// Incremental is a sink that writes to a sheet with Sync rather than
+// replacing its contents
+type Incremental struct {
+	sheet *GSheet
+}
+
+// NewIncremental returns a sink that updates sheets in place
+func NewIncremental(g *GSheet) *Incremental {
+	return &Incremental{sheet: g}
+}
+
+// Write brings the named sheet up to date with the input artifacts
+func (i *Incremental) Write(ctx context.Context, name string, a artifact.Artifacts) error {
+	return i.sheet.Sync(name, a)
+}
+
+// Read returns the artifacts currently in the named sheet
+func (i *Incremental) Read(ctx context.Context, name string) (artifact.Artifacts, error) {
+	return i.sheet.Read(ctx, name)
+}
+
+// DryRun returns a description of what Write would do to the named sheet
+func (i *Incremental) DryRun(ctx context.Context, name string, a artifact.Artifacts) (string, error) {
+	p, err := i.sheet.PlanSync(name, a)
+	if err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
+// rowRange returns the values that write an artifact into a row, by index
+func (c changes) rowRange(name string, row int, art artifact.Artifact) *sheets.ValueRange {
+	return &sheets.ValueRange{
+		Range:  span(name, row, len(c.columns)),
+		Values: [][]interface{}{art.Row(c.columns)},
+	}
+}
+
+// span returns the A1 notation for the first columns of a row, by index
+func span(name string, row, columns int) string {
+	return fmt.Sprintf("%s!A%d:%s%d", name, row+1, columnName(columns), row+1)
+}
+
+// columnName returns the letters naming a column, counting from 1 for A
+func columnName(n int) string {
+	result := ""
+	for n > 0 {
+		n--
+		result = string(rune('A'+n%26)) + result
+		n /= 26
+	}
+	return result
+}
+
+// same reports whether a row read back from a sheet already shows the
+// artifact in the input columns
+func same(cur, art artifact.Artifact, columns []string) bool {
+	y1, m1, d1 := cur.ShippedDate.Date()
+	y2, m2, d2 := art.ShippedDate.Date()
+
+	// columns past the standard ones hold the extra value and attributes
+	for _, col := range columns[len(artifact.Header):] {
+		if cur.Value(col) != art.Value(col) {
+			return false
+		}
+	}
+
+	return art.SameLink(cur.Link) &&
+		cur.Type == art.Type &&
+		cur.Project == art.Project &&
+		cur.Subproject == art.Subproject &&
+		cur.Title == art.Title &&
+		cur.Role == art.Role &&
+		y1 == y2 && m1 == m2 && d1 == d2
+}
+
+func hasValues(row *sheets.RowData) bool {
+	for _, v := range row.Values {
+		if v != nil && v.EffectiveValue != nil {
+			return true
+		}
+	}
+	return false
+}
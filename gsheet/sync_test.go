@@ -0,0 +1,171 @@
+package gsheet
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tpryan/work/artifact"
+)
+
+func TestGSheetSync(t *testing.T) {
+	keep := artifact.Artifact{
+		Type:        "Doc",
+		Project:     "Proj",
+		Title:       "Kept",
+		ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC),
+		Link:        "https://example.com/kept",
+	}
+	fresh := artifact.Artifact{
+		Title:       "Fresh",
+		ShippedDate: time.Date(2023, 8, 22, 0, 0, 0, 0, time.UTC),
+		Link:        "https://example.com/fresh",
+	}
+	renamed := keep
+	renamed.Title = "Renamed"
+	filed := keep
+	filed.Attributes = map[string]string{"Folder": "My Drive/Design"}
+
+	header := []string{"Type", "Project", "Subproject", "Title", "Role", "Shipped Date", "Link", "Notes"}
+	keepRow := []string{"Doc", "Proj", "", "Kept", "", "08/21/2023", `=HYPERLINK("https://example.com/kept","https://example.com/kept")`, "keep me"}
+	goneRow := []string{"Doc", "", "", "Gone", "", "08/20/2023", `=HYPERLINK("https://example.com/gone","https://example.com/gone")`, "bye"}
+	freshRow := []string{"", "", "", "Fresh", "", "08/22/2023", `=HYPERLINK("https://example.com/fresh","https://example.com/fresh")`}
+
+	tests := map[string]struct {
+		start [][]string
+		in    artifact.Artifacts
+		want  [][]string
+	}{
+		"newsheet": {
+			in: artifact.Artifacts{fresh},
+			want: [][]string{
+				header[:7],
+				freshRow,
+			},
+		},
+		"unchanged": {
+			start: [][]string{header, keepRow},
+			in:    artifact.Artifacts{keep},
+			want:  [][]string{header, keepRow},
+		},
+		"addremove": {
+			start: [][]string{header, goneRow, keepRow},
+			in:    artifact.Artifacts{keep, fresh},
+			want:  [][]string{header, keepRow, freshRow},
+		},
+		"changed": {
+			start: [][]string{header, keepRow},
+			in:    artifact.Artifacts{renamed},
+			want: [][]string{
+				header,
+				{"Doc", "Proj", "", "Renamed", "", "08/21/2023", `=HYPERLINK("https://example.com/kept","https://example.com/kept")`, "keep me"},
+			},
+		},
+		"attributes": {
+			start: [][]string{header[:7], keepRow[:7]},
+			in:    artifact.Artifacts{filed},
+			want: [][]string{
+				append(append([]string{}, header[:7]...), "Folder"),
+				append(append([]string{}, keepRow[:7]...), "My Drive/Design"),
+			},
+		},
+		"prefixlinks": {
+			start: [][]string{
+				header,
+				{"PR", "", "", "One", "", "08/21/2023", `=HYPERLINK("https://github.com/o/r/pull/1","https://github.com/o/r/pull/1")`, "note for one"},
+				{"PR", "", "", "Twelve", "", "08/21/2023", `=HYPERLINK("https://github.com/o/r/pull/12","https://github.com/o/r/pull/12")`, "note for twelve"},
+			},
+			in: artifact.Artifacts{
+				{Type: "PR", Title: "Twelve", ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC), Link: "https://github.com/o/r/pull/12"},
+				{Type: "PR", Title: "One", ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC), Link: "https://github.com/o/r/pull/1"},
+			},
+			want: [][]string{
+				header,
+				{"PR", "", "", "One", "", "08/21/2023", `=HYPERLINK("https://github.com/o/r/pull/1","https://github.com/o/r/pull/1")`, "note for one"},
+				{"PR", "", "", "Twelve", "", "08/21/2023", `=HYPERLINK("https://github.com/o/r/pull/12","https://github.com/o/r/pull/12")`, "note for twelve"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tabs := []string{}
+			if tc.start != nil {
+				tabs = append(tabs, "Report")
+			}
+			srv, g := newTestGSheet(t, tabs...)
+			if tc.start != nil {
+				srv.SetValues("test-spreadsheet", "Report", tc.start)
+			}
+
+			if err := g.Sync("Report", tc.in); err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.Equal(t, tc.want, srv.Values("test-spreadsheet", "Report"))
+		})
+	}
+}
+
+func TestGSheetSyncFormatRows(t *testing.T) {
+	srv, g := newTestGSheet(t, "Report")
+	srv.SetValues("test-spreadsheet", "Report", [][]string{
+		{"Type", "Project", "Subproject", "Title", "Role", "Shipped Date", "Link"},
+		{"Notes from the team"},
+		{"Doc", "Proj", "Sub", "Gone", "", "08/20/2023", `=HYPERLINK("https://example.com/gone","https://example.com/gone")`},
+		{"Doc", "Proj", "Sub", "Kept", "", "08/21/2023", `=HYPERLINK("https://example.com/kept","https://example.com/kept")`},
+	})
+
+	err := g.Sync("Report", artifact.Artifacts{
+		{Type: "Doc", Project: "Proj", Subproject: "Sub", Title: "Kept", ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC), Link: "https://example.com/kept"},
+		{Title: "Fresh", ShippedDate: time.Date(2023, 8, 22, 0, 0, 0, 0, time.UTC), Link: "https://example.com/fresh"},
+	})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	assert.Equal(t, "Fresh", srv.Values("test-spreadsheet", "Report")[3][3])
+
+	highlighted := map[int64]bool{}
+	for _, req := range srv.Requests("test-spreadsheet") {
+		if req.RepeatCell == nil || req.RepeatCell.Fields != "userEnteredFormat.backgroundColorStyle" {
+			continue
+		}
+		if r := req.RepeatCell.Range; r.StartRowIndex > 0 {
+			highlighted[r.StartRowIndex] = true
+		}
+	}
+	assert.Equal(t, map[int64]bool{3: true}, highlighted, "only the row Fresh ends up in should be highlighted")
+}
+
+func TestGSheetPlanSync(t *testing.T) {
+	srv, g := newTestGSheet(t, "Report")
+	srv.SetValues("test-spreadsheet", "Report", [][]string{
+		{"Type", "Project", "Subproject", "Title", "Role", "Shipped Date", "Link"},
+		{"Doc", "", "", "Gone", "", "08/20/2023", `=HYPERLINK("https://example.com/gone","https://example.com/gone")`},
+		{"Doc", "", "", "Old", "", "08/21/2023", `=HYPERLINK("https://example.com/kept","https://example.com/kept")`},
+	})
+	before := srv.Values("test-spreadsheet", "Report")
+
+	got, err := NewIncremental(&g).DryRun(context.Background(), "Report", artifact.Artifacts{
+		{Type: "Doc", Title: "New", ShippedDate: time.Date(2023, 8, 21, 0, 0, 0, 0, time.UTC), Link: "https://example.com/kept"},
+		{Title: "Fresh", Link: "https://example.com/fresh"},
+	})
+	if err != nil {
+		t.Fatalf("got an error when expected none: %s", err)
+	}
+
+	for _, want := range []string{
+		"Report (existing sheet): 2 rows would be written",
+		"+ Fresh https://example.com/fresh",
+		"- Gone https://example.com/gone",
+		"~ New https://example.com/kept",
+		"deleteDimension ROWS 2-2",
+	} {
+		assert.True(t, strings.Contains(got, want), "expected %q in:\n%s", want, got)
+	}
+
+	assert.Equal(t, before, srv.Values("test-spreadsheet", "Report"), "plan should not change values")
+}
@@ -0,0 +1,66 @@
+package work
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tpryan/work/artifact"
+)
+
+// Sink types that can be referenced in the config file
+const (
+	SinkSheet = "sheet"
+	SinkFile  = "file"
+)
+
+// Modes a sheet destination can be written in. Replace clears the sheet and
+// rewrites it; incremental only touches rows that changed, leaving any extra
+// columns people have added alone.
+const (
+	ModeReplace     = "replace"
+	ModeIncremental = "incremental"
+)
+
+// Sink is anywhere a report of artifacts can be written
+type Sink interface {
+	Write(ctx context.Context, name string, arts artifact.Artifacts) error
+}
+
+// SinkFactory builds a Sink for a destination
+type SinkFactory func(dest Destination) (Sink, error)
+
+// SinkRegistry maps sink types to the factories that can build them
+type SinkRegistry map[string]SinkFactory
+
+// Register makes a sink type available to the config file
+func (r SinkRegistry) Register(kind string, f SinkFactory) {
+	r[kind] = f
+}
+
+// New builds the sink a destination writes to. Destinations that don't name
+// a sink are written to the spreadsheet.
+func (r SinkRegistry) New(dest Destination) (Sink, error) {
+	kind := dest.Sink
+	if kind == "" {
+		kind = SinkSheet
+	}
+
+	f, ok := r[kind]
+	if !ok {
+		return nil, fmt.Errorf("no sink registered for type %q", kind)
+	}
+
+	return f(dest)
+}
+
+// DryRunner is implemented by sinks that can describe what a Write would do
+// without changing anything
+type DryRunner interface {
+	DryRun(ctx context.Context, name string, arts artifact.Artifacts) (string, error)
+}
+
+// Reader is implemented by sinks that can read back what is currently in a
+// destination, so edits made there by hand can be kept
+type Reader interface {
+	Read(ctx context.Context, name string) (artifact.Artifacts, error)
+}
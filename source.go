@@ -0,0 +1,247 @@
+package work
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tpryan/work/artifact"
+)
+
+// Source types that can be referenced in the config file
+const (
+	SourceSheet  = "sheet"
+	SourceGithub = "github"
+	SourceDrive  = "drive"
+	SourceFile   = "file"
+)
+
+// Source is anything that can produce artifacts for the collector
+type Source interface {
+	Name() string
+	Collect(ctx context.Context, criteria Criteria) (artifact.Artifacts, error)
+}
+
+// Windowed is implemented by sources that only collect the part of their
+// history that falls between the criteria's Start and End. Sources that
+// don't implement it return everything they have whatever the criteria.
+type Windowed interface {
+	Windowed() bool
+}
+
+// SourceConfig describes a single source of artifacts in the config file. A
+// plain string is treated as the name of a sheet in the spreadsheet. Columns
+// maps the headers a sheet uses to artifact columns, for sheets exported by
+// tools that name them differently. SnapshotOnly sources just refresh their
+// snapshot and are left out of the report.
+type SourceConfig struct {
+	Name         string            `yaml:"name,omitempty"`
+	Type         string            `yaml:"type,omitempty"`
+	Snapshot     string            `yaml:"snapshot,omitempty"`
+	Path         string            `yaml:"path,omitempty"`
+	Columns      map[string]string `yaml:"columns,omitempty"`
+	Github       GithubConfig      `yaml:"github,omitempty"`
+	Drive        DriveConfig       `yaml:"drive,omitempty"`
+	SnapshotOnly bool              `yaml:"-"`
+}
+
+// UnmarshalYAML allows sources to be listed either as a bare sheet name or as
+// a full source definition
+func (s *SourceConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*s = SourceConfig{Name: name, Type: SourceSheet}
+		return nil
+	}
+
+	type plain SourceConfig
+	raw := plain{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	*s = SourceConfig(raw)
+	if s.Type == "" {
+		s.Type = SourceSheet
+	}
+
+	return nil
+}
+
+// SourceConfigs is a collection of SourceConfig items
+type SourceConfigs []SourceConfig
+
+// GithubConfig holds the settings for a github source. When no queries are
+// listed the github package's defaults are used. Include adds any of the
+// package's named extra queries: reviews, issues, assigned and commits. The
+// token used to sign in can be given directly, read from a file, or read
+// from an environment variable. BaseURL and UploadURL point the source at a
+// GitHub Enterprise Server, such as https://github.example.com/api/v3/.
+// Closed pull requests that weren't merged are given UnmergedType, or
+// dropped entirely with DropUnmerged.
+type GithubConfig struct {
+	User      string        `yaml:"user,omitempty"`
+	BaseURL   string        `yaml:"base_url,omitempty"`
+	UploadURL string        `yaml:"upload_url,omitempty"`
+	Queries   []GithubQuery `yaml:"queries,omitempty"`
+	Include   []string      `yaml:"include,omitempty"`
+	Token     string        `yaml:"token,omitempty"`
+	TokenFile string        `yaml:"token_file,omitempty"`
+	TokenEnv  string        `yaml:"token_env,omitempty"`
+
+	UnmergedType string `yaml:"unmerged_type,omitempty"`
+	DropUnmerged bool   `yaml:"drop_unmerged,omitempty"`
+
+	Projects []GithubProject `yaml:"projects,omitempty"`
+}
+
+// GithubProject assigns github work to a project. Work matches if it is in
+// one of the Repos, given as owner/repo or owner/* for all of an owner's
+// repositories, if its repository has one of the Topics, or if it has one of
+// the Labels. The first project that matches is used.
+type GithubProject struct {
+	Project    string   `yaml:"project,omitempty"`
+	Subproject string   `yaml:"subproject,omitempty"`
+	Repos      []string `yaml:"repos,omitempty"`
+	Topics     []string `yaml:"topics,omitempty"`
+	Labels     []string `yaml:"labels,omitempty"`
+}
+
+// GithubQuery is a github search and the Type and Role to give what it
+// finds. {user} in the query is replaced with the configured user. Kind is
+// either issues, which covers pull requests too, or commits.
+type GithubQuery struct {
+	Query string `yaml:"query,omitempty"`
+	Kind  string `yaml:"kind,omitempty"`
+	Type  string `yaml:"type,omitempty"`
+	Role  string `yaml:"role,omitempty"`
+}
+
+// DriveConfig holds the settings for a Google Drive source. Owner is the
+// email address whose files are collected, defaulting to whoever the
+// credentials belong to. The files searched for can be narrowed to Mimes,
+// to files in Folders (by ID), and by any extra drive query Clauses, such as
+// "modifiedTime > '2023-01-01'". Drives lists shared drive IDs to search as
+// well as My Drive, or "all" to search My Drive and every shared drive.
+// Files on shared drives have no owner, so the ones the user made the first
+// revision of count as theirs. Roles picks which of the files the user owns
+// (author), has edited (editor) or has commented on (reviewer) are
+// collected, defaulting to all of them. PageSize is how many files are
+// fetched per request, defaulting to the drive maximum of 1000. Shipped is
+// how owned files are dated: created (the default), modified (last modified
+// by the user), marker (first revision after a comment containing one of
+// Markers, "final" or "approved" by default) or published. Types are rules
+// for telling what kind of document a file is, tried in order before the
+// built-in ones. Noise lists the filters that drop files that aren't real
+// work: copies, untitled, templates, unedited and trashed. All of them are
+// used by default, and none of them if Noise is just "none". Templates only
+// drops files named as templates, like "[Template] ..." or "... Template";
+// templates-any, which isn't on by default, drops every file with template
+// anywhere in its name.
+type DriveConfig struct {
+	Owner    string          `yaml:"owner,omitempty"`
+	Mimes    []string        `yaml:"mimes,omitempty"`
+	Folders  []string        `yaml:"folders,omitempty"`
+	Clauses  []string        `yaml:"clauses,omitempty"`
+	Drives   []string        `yaml:"drives,omitempty"`
+	Roles    []string        `yaml:"roles,omitempty"`
+	PageSize int             `yaml:"page_size,omitempty"`
+	Shipped  string          `yaml:"shipped,omitempty"`
+	Markers  []string        `yaml:"markers,omitempty"`
+	Types    []DriveTypeRule `yaml:"types,omitempty"`
+	Noise    []string        `yaml:"noise,omitempty"`
+}
+
+// DriveTypeRule gives drive files a Type when they match every one of its
+// conditions that is set: a regular expression for the Title, an exact Mime
+// type, a regular expression for the Folder path, such as "My Drive/Design",
+// and the ID of a drive Label applied to the file.
+type DriveTypeRule struct {
+	Type   string `yaml:"type,omitempty"`
+	Title  string `yaml:"title,omitempty"`
+	Mime   string `yaml:"mime,omitempty"`
+	Folder string `yaml:"folder,omitempty"`
+	Label  string `yaml:"label,omitempty"`
+}
+
+// SourceFactory builds a Source from its configuration
+type SourceFactory func(cfg SourceConfig) (Source, error)
+
+// SourceRegistry maps source types to the factories that can build them
+type SourceRegistry map[string]SourceFactory
+
+// Register makes a source type available to the config file
+func (r SourceRegistry) Register(kind string, f SourceFactory) {
+	r[kind] = f
+}
+
+// New builds the source described by the input config
+func (r SourceRegistry) New(cfg SourceConfig) (Source, error) {
+	f, ok := r[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("no source registered for type %q", cfg.Type)
+	}
+
+	return f(cfg)
+}
+
+// SourceList returns every source the config enables, including the ones
+// implied by the older github_user and query_drive settings. Those sources
+// use the github and drive settings from the config, with the github user
+// defaulting to github_user. The drive owner is left to default to whoever
+// the credentials belong to, unless drive_domain is set, in which case it
+// is the user at that domain. As before they were sources, the implied ones
+// only make it into the report if their snapshot sheet is listed in the
+// sources; otherwise they just refresh it.
+func (c Config) SourceList(user string) SourceConfigs {
+	result := SourceConfigs{}
+	snapshots := map[string]bool{}
+
+	listed := map[string]bool{}
+	for _, s := range c.Sources {
+		if s.Type == SourceSheet {
+			listed[s.Name] = true
+		}
+	}
+
+	if c.GithubUser != "" {
+		github := c.Github
+		if github.User == "" {
+			github.User = c.GithubUser
+		}
+
+		result = append(result, SourceConfig{
+			Name:         "Source - Github",
+			Type:         SourceGithub,
+			Snapshot:     "Source - Github",
+			Github:       github,
+			SnapshotOnly: !listed["Source - Github"],
+		})
+		snapshots["Source - Github"] = true
+	}
+
+	if c.QueryDrive {
+		drive := c.Drive
+		if drive.Owner == "" && c.DriveDomain != "" {
+			drive.Owner = fmt.Sprintf("%s@%s", user, c.DriveDomain)
+		}
+
+		result = append(result, SourceConfig{
+			Name:         "Source - DriveFiles",
+			Type:         SourceDrive,
+			Snapshot:     "Source - DriveFiles",
+			Drive:        drive,
+			SnapshotOnly: !listed["Source - DriveFiles"],
+		})
+		snapshots["Source - DriveFiles"] = true
+	}
+
+	for _, s := range c.Sources {
+		// the snapshot of an implied source is already covered by the source
+		if s.Type == SourceSheet && snapshots[s.Name] {
+			continue
+		}
+		result = append(result, s)
+	}
+
+	return result
+}
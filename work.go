@@ -5,6 +5,7 @@ package work
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/tpryan/work/artifact"
@@ -16,10 +17,14 @@ import (
 type Config struct {
 	SpreadSheetID string               `yaml:"spread_sheet_id,omitempty"`
 	GithubUser    string               `yaml:"github_user,omitempty"`
+	Github        GithubConfig         `yaml:"github,omitempty"`
 	Destinations  Destinations         `yaml:"destinations,omitempty"`
-	Sources       []string             `yaml:"sources,omitempty"`
+	Sources       SourceConfigs        `yaml:"sources,omitempty"`
 	Classifiers   artifact.Classifiers `yaml:"classifiers,omitempty"`
 	QueryDrive    bool                 `yaml:"query_drive,omitempty"`
+	Drive         DriveConfig          `yaml:"drive,omitempty"`
+	DriveDomain   string               `yaml:"drive_domain,omitempty"`
+	Overrides     SourceConfig         `yaml:"overrides,omitempty"`
 }
 
 // NewConfig returna a config from a given path
@@ -41,15 +46,52 @@ func NewConfig(path string) (*Config, error) {
 
 // Destination is a place to write a report based on the criteria
 type Destination struct {
-	Sheet    string   `yaml:"sheet,omitempty"`
-	Sort     string   `yaml:"sort,omitempty"`
-	Summary  bool     `yaml:"summary,omitempty"`
-	Criteria Criteria `yaml:"criteria,omitempty"`
+	Sheet     string   `yaml:"sheet,omitempty"`
+	Sink      string   `yaml:"sink,omitempty"`
+	Path      string   `yaml:"path,omitempty"`
+	Mode      string   `yaml:"mode,omitempty"`
+	Sort      string   `yaml:"sort,omitempty"`
+	Summary   bool     `yaml:"summary,omitempty"`
+	KeepEdits bool     `yaml:"keep_edits,omitempty"`
+	Criteria  Criteria `yaml:"criteria,omitempty"`
+}
+
+// ClassifiedSheet returns the name of the sheet that records what
+// classification gave the artifacts last written to a destination that
+// keeps edits, so hand edits can be told apart from it
+func (d Destination) ClassifiedSheet() string {
+	name := d.Sheet
+	if name == "" {
+		name = filepath.Base(d.Path)
+	}
+	return fmt.Sprintf("%s - Classified", name)
 }
 
 // Destinations is a collection of destination items
 type Destinations []Destination
 
+// Window returns the span of time covering every destination, or empty
+// criteria if any destination isn't limited to one, so sources need only
+// collect what some destination will keep
+func (d Destinations) Window() Criteria {
+	result := Criteria{}
+
+	for i, dest := range d {
+		if dest.Criteria.Start.IsZero() || dest.Criteria.End.IsZero() {
+			return Criteria{}
+		}
+
+		if i == 0 || dest.Criteria.Start.Before(result.Start) {
+			result.Start = dest.Criteria.Start
+		}
+		if i == 0 || dest.Criteria.End.After(result.End) {
+			result.End = dest.Criteria.End
+		}
+	}
+
+	return result
+}
+
 // Criteria are the filters to match a Destination
 type Criteria struct {
 	Start   time.Time `yaml:"start,omitempty"`
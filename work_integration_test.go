@@ -65,7 +65,10 @@ func TestBasic(t *testing.T) {
 			in: "testdata/basic.yaml",
 			want: &Config{
 				SpreadSheetID: "123456789",
-				Sources:       []string{"Critique", "Buganizer"},
+				Sources: SourceConfigs{
+					{Name: "Critique", Type: SourceSheet},
+					{Name: "Buganizer", Type: SourceSheet},
+				},
 				Destinations: Destinations{
 					Destination{
 						Sheet: "Test",
@@ -1 +1,378 @@
 package work
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tpryan/work/artifact"
+	"gopkg.in/yaml.v2"
+)
+
+type fakeSource struct {
+	name string
+}
+
+func (f fakeSource) Name() string {
+	return f.name
+}
+
+func (f fakeSource) Collect(ctx context.Context, criteria Criteria) (artifact.Artifacts, error) {
+	return artifact.Artifacts{}, nil
+}
+
+func TestSourceConfigUnmarshal(t *testing.T) {
+	tests := map[string]struct {
+		in     string
+		want   SourceConfigs
+		errStr string
+	}{
+		"names": {
+			in: "- Critique\n- Buganizer\n",
+			want: SourceConfigs{
+				{Name: "Critique", Type: SourceSheet},
+				{Name: "Buganizer", Type: SourceSheet},
+			},
+		},
+		"mixed": {
+			in: "- Critique\n- name: Github\n  type: github\n  snapshot: Source - Github\n  github:\n    user: tpryan\n",
+			want: SourceConfigs{
+				{Name: "Critique", Type: SourceSheet},
+				{
+					Name:     "Github",
+					Type:     SourceGithub,
+					Snapshot: "Source - Github",
+					Github:   GithubConfig{User: "tpryan"},
+				},
+			},
+		},
+		"githubqueries": {
+			in: "- name: Github\n  type: github\n  github:\n    user: tpryan\n    queries:\n    - query: reviewed-by:{user} is:pr\n      role: reviewer\n    - query: author:{user}\n      kind: commits\n",
+			want: SourceConfigs{
+				{
+					Name: "Github",
+					Type: SourceGithub,
+					Github: GithubConfig{
+						User: "tpryan",
+						Queries: []GithubQuery{
+							{Query: "reviewed-by:{user} is:pr", Role: "reviewer"},
+							{Query: "author:{user}", Kind: "commits"},
+						},
+					},
+				},
+			},
+		},
+		"columns": {
+			in: "- name: Critique\n  columns:\n    CL: Link\n    Submitted: Shipped Date\n",
+			want: SourceConfigs{
+				{
+					Name:    "Critique",
+					Type:    SourceSheet,
+					Columns: map[string]string{"CL": "Link", "Submitted": "Shipped Date"},
+				},
+			},
+		},
+		"defaulttype": {
+			in: "- name: Critique\n",
+			want: SourceConfigs{
+				{Name: "Critique", Type: SourceSheet},
+			},
+		},
+		"garbage": {
+			in:     "- name: [Critique\n",
+			errStr: "yaml",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := SourceConfigs{}
+			err := yaml.Unmarshal([]byte(tc.in), &got)
+
+			if tc.errStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+					t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSourceRegistryNew(t *testing.T) {
+	registry := SourceRegistry{}
+	registry.Register(SourceSheet, func(cfg SourceConfig) (Source, error) {
+		return fakeSource{name: cfg.Name}, nil
+	})
+
+	tests := map[string]struct {
+		in     SourceConfig
+		want   Source
+		errStr string
+	}{
+		"registered": {
+			in:   SourceConfig{Name: "Critique", Type: SourceSheet},
+			want: fakeSource{name: "Critique"},
+		},
+		"unregistered": {
+			in:     SourceConfig{Name: "Github", Type: SourceGithub},
+			errStr: "no source registered for type \"github\"",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := registry.New(tc.in)
+
+			if tc.errStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+					t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestConfigSourceList(t *testing.T) {
+	tests := map[string]struct {
+		in   Config
+		user string
+		want SourceConfigs
+	}{
+		"sheets": {
+			in: Config{
+				Sources: SourceConfigs{
+					{Name: "Critique", Type: SourceSheet},
+				},
+			},
+			want: SourceConfigs{
+				{Name: "Critique", Type: SourceSheet},
+			},
+		},
+		"legacy": {
+			in: Config{
+				GithubUser: "tpryan",
+				QueryDrive: true,
+				Sources: SourceConfigs{
+					{Name: "Critique", Type: SourceSheet},
+					{Name: "Source - Github", Type: SourceSheet},
+					{Name: "Source - DriveFiles", Type: SourceSheet},
+				},
+			},
+			user: "tpryan",
+			want: SourceConfigs{
+				{
+					Name:     "Source - Github",
+					Type:     SourceGithub,
+					Snapshot: "Source - Github",
+					Github:   GithubConfig{User: "tpryan"},
+				},
+				{
+					Name:     "Source - DriveFiles",
+					Type:     SourceDrive,
+					Snapshot: "Source - DriveFiles",
+				},
+				{Name: "Critique", Type: SourceSheet},
+			},
+		},
+		"githubconfig": {
+			in: Config{
+				GithubUser: "tpryan",
+				Github: GithubConfig{
+					TokenEnv: "GITHUB_TOKEN",
+					Include:  []string{"reviews"},
+				},
+				Sources: SourceConfigs{{Name: "Source - Github", Type: SourceSheet}},
+			},
+			want: SourceConfigs{
+				{
+					Name:     "Source - Github",
+					Type:     SourceGithub,
+					Snapshot: "Source - Github",
+					Github: GithubConfig{
+						User:     "tpryan",
+						TokenEnv: "GITHUB_TOKEN",
+						Include:  []string{"reviews"},
+					},
+				},
+			},
+		},
+		"driveconfig": {
+			in: Config{
+				QueryDrive: true,
+				Drive: DriveConfig{
+					Owner:   "someone@example.com",
+					Clauses: []string{"modifiedDate > '2023-01-01'"},
+				},
+			},
+			user: "tpryan",
+			want: SourceConfigs{
+				{
+					Name:     "Source - DriveFiles",
+					Type:     SourceDrive,
+					Snapshot: "Source - DriveFiles",
+					Drive: DriveConfig{
+						Owner:   "someone@example.com",
+						Clauses: []string{"modifiedDate > '2023-01-01'"},
+					},
+					SnapshotOnly: true,
+				},
+			},
+		},
+		"drivedomain": {
+			in: Config{
+				QueryDrive:  true,
+				DriveDomain: "example.com",
+			},
+			user: "tpryan",
+			want: SourceConfigs{
+				{
+					Name:         "Source - DriveFiles",
+					Type:         SourceDrive,
+					Snapshot:     "Source - DriveFiles",
+					Drive:        DriveConfig{Owner: "tpryan@example.com"},
+					SnapshotOnly: true,
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tc.in.SourceList(tc.user)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+type fakeSink struct {
+	kind string
+}
+
+func (f fakeSink) Write(ctx context.Context, name string, arts artifact.Artifacts) error {
+	return nil
+}
+
+func TestSinkRegistryNew(t *testing.T) {
+	sinks := SinkRegistry{}
+	sinks.Register(SinkSheet, func(dest Destination) (Sink, error) {
+		return fakeSink{kind: SinkSheet}, nil
+	})
+
+	tests := map[string]struct {
+		in     Destination
+		want   Sink
+		errStr string
+	}{
+		"default": {
+			in:   Destination{Sheet: "Test"},
+			want: fakeSink{kind: SinkSheet},
+		},
+		"named": {
+			in:   Destination{Sheet: "Test", Sink: SinkSheet},
+			want: fakeSink{kind: SinkSheet},
+		},
+		"unregistered": {
+			in:     Destination{Sheet: "Test", Sink: "slack"},
+			errStr: "no sink registered for type \"slack\"",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := sinks.New(tc.in)
+
+			if tc.errStr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errStr) {
+					t.Fatalf("expected error containing %q, got: %v", tc.errStr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got an error when expected none: %s", err)
+			}
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestDestinationsWindow(t *testing.T) {
+	jan := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	dec := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		in   Destinations
+		want Criteria
+	}{
+		"none": {
+			in:   Destinations{},
+			want: Criteria{},
+		},
+		"single": {
+			in:   Destinations{{Criteria: Criteria{Start: jan, End: jul}}},
+			want: Criteria{Start: jan, End: jul},
+		},
+		"span": {
+			in: Destinations{
+				{Criteria: Criteria{Start: jul, End: dec}},
+				{Criteria: Criteria{Start: jan, End: jul, Project: "Proj"}},
+			},
+			want: Criteria{Start: jan, End: dec},
+		},
+		"unbounded": {
+			in: Destinations{
+				{Criteria: Criteria{Start: jan, End: jul}},
+				{Criteria: Criteria{Start: jul}},
+			},
+			want: Criteria{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tc.in.Window()
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestDestinationClassifiedSheet(t *testing.T) {
+	tests := map[string]struct {
+		in   Destination
+		want string
+	}{
+		"sheet": {
+			in:   Destination{Sheet: "2023 Annual"},
+			want: "2023 Annual - Classified",
+		},
+		"file": {
+			in:   Destination{Sink: SinkFile, Path: "/tmp/reports/annual.csv"},
+			want: "annual.csv - Classified",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tc.in.ClassifiedSheet()
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}